@@ -0,0 +1,119 @@
+// Package boltstore implements a subnetcalc.Store keeping trees in a bbolt database file
+package boltstore
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"time"
+
+	"github.com/kschjeld/subnetcalc"
+	bolt "go.etcd.io/bbolt"
+)
+
+var bucket = []byte("subnetcalc")
+
+// Store keeps a named tree in a bbolt database file. bbolt locks the file for as long as it is open, so it is
+// only opened for the duration of each operation, allowing several processes to share it.
+type Store struct {
+	path    string
+	name    []byte
+	timeout time.Duration
+}
+
+// New returns a Store for the tree with the given name in the database file at path, which is created on the
+// first save. Operations wait up to timeout for other processes to close the file.
+func New(path, name string, timeout time.Duration) *Store {
+	return &Store{path: path, name: []byte(name), timeout: timeout}
+}
+
+// Load returns the stored tree and its version. The file is opened read-only, so loads do not block each other.
+func (s *Store) Load() (*subnetcalc.Subnet, uint64, error) {
+	db, err := bolt.Open(s.path, 0o644, &bolt.Options{Timeout: s.timeout, ReadOnly: true})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, subnetcalc.ErrEmptyStore
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer db.Close()
+
+	var data []byte
+	var version uint64
+
+	err = db.View(func(tx *bolt.Tx) error {
+		data, version = s.get(tx)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if version == 0 {
+		return nil, 0, subnetcalc.ErrEmptyStore
+	}
+
+	root, err := subnetcalc.ParseJSON(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return root, version, nil
+}
+
+// Save stores the tree unconditionally
+func (s *Store) Save(root *subnetcalc.Subnet) (uint64, error) {
+	return s.store(root, func(uint64) bool { return true })
+}
+
+// CompareAndSwap stores the tree if the stored version is unchanged
+func (s *Store) CompareAndSwap(version uint64, root *subnetcalc.Subnet) (uint64, error) {
+	return s.store(root, func(current uint64) bool { return current == version })
+}
+
+func (s *Store) store(root *subnetcalc.Subnet, check func(current uint64) bool) (uint64, error) {
+	data, err := json.Marshal(root)
+	if err != nil {
+		return 0, err
+	}
+
+	db, err := bolt.Open(s.path, 0o644, &bolt.Options{Timeout: s.timeout})
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var version uint64
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, version = s.get(tx); !check(version) {
+			return subnetcalc.ErrVersionConflict
+		}
+		version++
+
+		b, err := tx.CreateBucketIfNotExists(bucket)
+		if err != nil {
+			return err
+		}
+
+		value := make([]byte, 8, 8+len(data))
+		binary.BigEndian.PutUint64(value, version)
+		return b.Put(s.name, append(value, data...))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// get returns the stored tree and version, stored as a big endian version followed by the JSON encoded tree
+func (s *Store) get(tx *bolt.Tx) ([]byte, uint64) {
+	b := tx.Bucket(bucket)
+	if b == nil {
+		return nil, 0
+	}
+
+	value := b.Get(s.name)
+	if len(value) < 8 {
+		return nil, 0
+	}
+	return append([]byte{}, value[8:]...), binary.BigEndian.Uint64(value)
+}
@@ -0,0 +1,81 @@
+package boltstore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kschjeld/subnetcalc"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Store(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subnets.db")
+	store := New(path, "prod", time.Second)
+
+	_, _, err := store.Load()
+	assert.ErrorIs(t, err, subnetcalc.ErrEmptyStore)
+
+	s, err := subnetcalc.Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	_, err = store.CompareAndSwap(1, s)
+	assert.ErrorIs(t, err, subnetcalc.ErrVersionConflict)
+
+	version, err := store.CompareAndSwap(0, s)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+
+	err = subnetcalc.Update(store, func(root *subnetcalc.Subnet) error {
+		_, err := root.FindFreeAndReserve(24, "web")
+		return err
+	})
+	assert.NoError(t, err)
+
+	loaded, version, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), version)
+	assert.Equal(t, "10.0.0.0/24", loaded.FindByName("web")[0].CIDR())
+
+	_, err = store.CompareAndSwap(1, loaded)
+	assert.ErrorIs(t, err, subnetcalc.ErrVersionConflict)
+
+	version, err = store.Save(loaded)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), version)
+
+	other := New(path, "dev", time.Second)
+	_, _, err = other.Load()
+	assert.ErrorIs(t, err, subnetcalc.ErrEmptyStore, "trees are stored by name")
+}
+
+func Test_SharedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subnets.db")
+	a := New(path, "prod", time.Second)
+	b := New(path, "prod", time.Second)
+
+	s, err := subnetcalc.Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = a.Save(s)
+	assert.NoError(t, err)
+
+	err = subnetcalc.Update(b, func(root *subnetcalc.Subnet) error {
+		_, err := root.FindFreeAndReserve(24, "web")
+		return err
+	})
+	assert.NoError(t, err)
+
+	_, err = a.CompareAndSwap(1, s)
+	assert.ErrorIs(t, err, subnetcalc.ErrVersionConflict)
+
+	err = subnetcalc.Update(a, func(root *subnetcalc.Subnet) error {
+		_, err := root.FindFreeAndReserve(24, "db")
+		return err
+	})
+	assert.NoError(t, err)
+
+	loaded, version, err := b.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), version)
+	assert.Equal(t, "10.0.1.0/24", loaded.FindByName("db")[0].CIDR())
+}
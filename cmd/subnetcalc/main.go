@@ -0,0 +1,90 @@
+// Command subnetcalc works with subnet trees saved as JSON, either by MarshalJSON or in a FileStore.
+//
+// Usage:
+//
+//	subnetcalc overlaps FILE...
+//
+// The overlaps command reports reservations intersecting across the trees in the files, and exits with status 1
+// if there are any.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kschjeld/subnetcalc"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	switch args[0] {
+	case "overlaps":
+		return overlaps(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		usage(stdout)
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "subnetcalc: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: subnetcalc overlaps FILE...")
+}
+
+func overlaps(files []string, stdout, stderr io.Writer) int {
+	if len(files) < 2 {
+		fmt.Fprintln(stderr, "subnetcalc: overlaps needs at least two files")
+		return 2
+	}
+
+	var trees []*subnetcalc.Subnet
+	for _, file := range files {
+		tree, err := load(file)
+		if err != nil {
+			fmt.Fprintf(stderr, "subnetcalc: %s: %v\n", file, err)
+			return 2
+		}
+		trees = append(trees, tree)
+	}
+
+	found := subnetcalc.CheckOverlaps(trees...)
+	for _, o := range found {
+		fmt.Fprintf(stdout, "%s: %s %q overlaps %s: %s %q\n",
+			files[o.TreeA], o.A.CIDR(), o.A.Reservation(), files[o.TreeB], o.B.CIDR(), o.B.Reservation())
+	}
+
+	if len(found) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// load reads a tree from a JSON snapshot or a FileStore file
+func load(file string) (*subnetcalc.Subnet, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored struct {
+		Tree json.RawMessage `json:"tree"`
+	}
+	if err = json.Unmarshal(data, &stored); err == nil && stored.Tree != nil {
+		data = stored.Tree
+	}
+
+	return subnetcalc.ParseJSON(data)
+}
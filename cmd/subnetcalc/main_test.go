@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kschjeld/subnetcalc"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Overlaps(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	assert.NoError(t, os.WriteFile(a, []byte(`{"root":"10.0.0.0/16","reservations":[{"cidr":"10.0.0.0/24","name":"web"},{"cidr":"10.0.1.0/24","name":"db"}]}`), 0o644))
+	b := filepath.Join(dir, "b.json")
+	assert.NoError(t, os.WriteFile(b, []byte(`{"root":"10.0.0.0/16","reservations":[{"cidr":"10.0.1.128/25","name":"cache"}]}`), 0o644))
+
+	// A FileStore file with a tree not overlapping the others
+	stored := filepath.Join(dir, "c.json")
+	c, err := subnetcalc.Parse("10.1.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = c.AddReservation("10.1.0.0/24", "other")
+	assert.NoError(t, err)
+	_, err = subnetcalc.NewFileStore(stored).Save(c)
+	assert.NoError(t, err)
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 1, run([]string{"overlaps", a, b, stored}, &stdout, &stderr))
+	assert.Equal(t, a+`: 10.0.1.0/24 "db" overlaps `+b+`: 10.0.1.128/25 "cache"`+"\n", stdout.String())
+	assert.Empty(t, stderr.String())
+
+	stdout.Reset()
+	assert.Equal(t, 0, run([]string{"overlaps", a, stored}, &stdout, &stderr))
+	assert.Empty(t, stdout.String())
+}
+
+func Test_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 2, run(nil, &stdout, &stderr))
+	assert.Equal(t, 2, run([]string{"unknown"}, &stdout, &stderr))
+	assert.Equal(t, 2, run([]string{"overlaps", "a.json"}, &stdout, &stderr))
+	assert.Equal(t, 2, run([]string{"overlaps", "missing-a.json", "missing-b.json"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "missing-a.json")
+}
@@ -0,0 +1,82 @@
+package subnetcalc
+
+import (
+	"hash/fnv"
+)
+
+// FindFreeDeterministic searches for an available subnet of the given size at a position derived from hashing
+// the key. If the preferred subnet is taken, the following subnets of the same size are probed, wrapping around
+// at the end. Given the same reservations, a key always maps to the same subnet, regardless of the order the
+// keys were allocated in, unless their preferred positions collide.
+func (s *Subnet) FindFreeDeterministic(size int, key string) (*Subnet, error) {
+	return s.findFreeDeterministic(size, key, nil)
+}
+
+// findFreeDeterministic searches like FindFreeDeterministic, skipping available subnets not accepted by the
+// allowed function
+func (s *Subnet) findFreeDeterministic(size int, key string, allowed func(s *Subnet) bool) (*Subnet, error) {
+	if size < s.Size() || size > 32 {
+		return nil, &NotFoundError{Root: s.CIDR(), Size: size}
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	count := uint64(1) << (size - s.Size())
+	start := h.Sum64() % count
+	for i := uint64(0); i < count; i++ {
+		if sn := s.freeAt(size, (start+i)%count, allowed); sn != nil {
+			return sn, nil
+		}
+	}
+
+	return nil, &NotFoundError{Root: s.CIDR(), Size: size}
+}
+
+// FindFreeDeterministicAndReserve combines FindFreeDeterministic and Reserve into one operation, using the
+// reservation name as key. Available subnets where the reservation would violate the policies of the tree are
+// probed past.
+func (s *Subnet) FindFreeDeterministicAndReserve(size int, name string) (*Subnet, error) {
+	sn, err := s.findFreeDeterministic(size, name, func(sn *Subnet) bool {
+		return sn.checkPolicies(name) == nil
+	})
+	if err != nil {
+		// No subnet satisfies the policies, reserving the preferred available one reports why
+		if sn, err = s.FindFreeDeterministic(size, name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = sn.Reserve(name); err != nil {
+		return nil, err
+	}
+
+	return sn, nil
+}
+
+// freeAt returns the subnet of the given size at position index within the subnet, or nil if it is not free or
+// not accepted by the allowed function. As with FindFree, a pool is searched within while other reservations are
+// not.
+func (s *Subnet) freeAt(size int, index uint64, allowed func(s *Subnet) bool) *Subnet {
+	node := s
+	for bit := size - s.Size() - 1; bit >= 0; bit-- {
+		if node.reserved && !(node == s && s.pool) {
+			return nil
+		}
+
+		_ = node.divide()
+		if index>>bit&1 == 0 {
+			node = node.low()
+		} else {
+			node = node.high()
+		}
+	}
+
+	if node.reserved || node.subReservations > 0 {
+		return nil
+	}
+	if allowed != nil && !allowed(node) {
+		return nil
+	}
+	return node
+}
@@ -0,0 +1,105 @@
+package subnetcalc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FindFreeDeterministic(t *testing.T) {
+	names := []string{"dev", "staging", "prod", "qa", "perf", "demo"}
+
+	allocate := func(order []string) map[string]string {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		res := map[string]string{}
+		for _, name := range order {
+			sn, err := s.FindFreeDeterministicAndReserve(24, name)
+			assert.NoError(t, err)
+			res[name] = sn.CIDR()
+		}
+		return res
+	}
+
+	t.Run("Independent of order", func(t *testing.T) {
+		reversed := make([]string, len(names))
+		for i, name := range names {
+			reversed[len(names)-1-i] = name
+		}
+		assert.Equal(t, allocate(names), allocate(reversed))
+	})
+
+	t.Run("Stable", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.FindFreeDeterministic(24, "prod")
+		assert.NoError(t, err)
+		assert.Equal(t, allocate(names)["prod"], sn.CIDR())
+		assert.Equal(t, "", sn.Reservation(), "finding does not reserve")
+	})
+
+	t.Run("Probes past taken subnets", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		preferred, err := s.FindFreeDeterministic(24, "prod")
+		assert.NoError(t, err)
+		assert.NoError(t, preferred.Reserve("squatter"))
+
+		sn, err := s.FindFreeDeterministic(24, "prod")
+		assert.NoError(t, err)
+		assert.NotEqual(t, preferred, sn)
+	})
+
+	t.Run("Wraps around until full", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/28")
+		assert.NoError(t, err, "parse should return no error")
+
+		for i := 0; i < 4; i++ {
+			_, err = s.FindFreeDeterministicAndReserve(30, fmt.Sprintf("link-%d", i))
+			assert.NoError(t, err)
+		}
+		assert.Len(t, s.Collect(SelectReserved()), 4)
+
+		_, err = s.FindFreeDeterministic(30, "link-4")
+		assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+	})
+
+	t.Run("Reserved parent", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		_, err = s.AddReservation("10.0.0.0/17", "low")
+		assert.NoError(t, err)
+
+		for _, name := range names {
+			sn, err := s.FindFreeDeterministic(24, name)
+			assert.NoError(t, err)
+			assert.True(t, sn.within(s.high()), "%s should be in the free half", sn.CIDR())
+		}
+	})
+
+	t.Run("Pool", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		pool, err := s.AddReservation("10.0.16.0/20", "team")
+		assert.NoError(t, err)
+		assert.NoError(t, pool.SetPool(true))
+
+		sn, err := pool.FindFreeDeterministicAndReserve(24, "team-a")
+		assert.NoError(t, err)
+		assert.True(t, sn.within(pool))
+	})
+
+	t.Run("Invalid size", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		_, err = s.FindFreeDeterministic(8, "prod")
+		assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+		_, err = s.FindFreeDeterministic(33, "prod")
+		assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+	})
+}
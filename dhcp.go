@@ -0,0 +1,223 @@
+package subnetcalc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrInvalidDHCPOptions = errors.New("invalid DHCP options")
+
+// DHCPOptions describes the DHCP scope generated for a reserved subnet
+type DHCPOptions struct {
+	GatewayOffset     int               // Offset of the gateway from the network address, 0 for no gateway
+	DNSServers        []string          // DNS server addresses handed out to clients
+	ExcludeStart      string            // First address of a range left out of the dynamic pool
+	ExcludeEnd        string            // Last address of the excluded range, defaults to ExcludeStart
+	LeaseTime         time.Duration     // Lease time, left to the server default if zero
+	ID                int               // Kea subnet id, left to Kea if zero
+	Tag               string            // dnsmasq tag, defaults to the reservation name
+	HardwareAddresses map[string]string // Hardware addresses of host allocations by reservation name
+}
+
+// dhcpScope is the scope of a reserved subnet resolved from DHCP options
+type dhcpScope struct {
+	gateway string
+	dns     []string
+	pools   [][2]int
+	hosts   []dhcpHost
+}
+
+type dhcpHost struct {
+	name, ip, hwAddress string
+}
+
+// WriteKea writes the subnet as an ISC Kea subnet4 entry, with the usable addresses except the gateway and the
+// excluded range as pools and host (/32) allocations with a hardware address as reservations
+func (s *Subnet) WriteKea(w io.Writer, opts DHCPOptions) error {
+	scope, err := s.dhcpScope(opts)
+	if err != nil {
+		return err
+	}
+
+	type keaOption struct {
+		Name string `json:"name"`
+		Data string `json:"data"`
+	}
+	type keaReservation struct {
+		HWAddress string `json:"hw-address"`
+		IPAddress string `json:"ip-address"`
+		Hostname  string `json:"hostname"`
+	}
+	entry := struct {
+		ID            int              `json:"id,omitempty"`
+		Subnet        string           `json:"subnet"`
+		Pools         []map[string]any `json:"pools"`
+		OptionData    []keaOption      `json:"option-data,omitempty"`
+		ValidLifetime int              `json:"valid-lifetime,omitempty"`
+		Reservations  []keaReservation `json:"reservations,omitempty"`
+		UserContext   map[string]any   `json:"user-context"`
+	}{
+		ID:            opts.ID,
+		Subnet:        s.CIDR(),
+		Pools:         []map[string]any{},
+		ValidLifetime: int(opts.LeaseTime.Seconds()),
+		UserContext:   map[string]any{"reservation": s.Reservation()},
+	}
+
+	for _, p := range scope.pools {
+		entry.Pools = append(entry.Pools, map[string]any{"pool": inetNToA(p[0]) + " - " + inetNToA(p[1])})
+	}
+	if scope.gateway != "" {
+		entry.OptionData = append(entry.OptionData, keaOption{Name: "routers", Data: scope.gateway})
+	}
+	if len(scope.dns) > 0 {
+		entry.OptionData = append(entry.OptionData, keaOption{Name: "domain-name-servers", Data: strings.Join(scope.dns, ", ")})
+	}
+	for _, h := range scope.hosts {
+		if h.hwAddress != "" {
+			entry.Reservations = append(entry.Reservations, keaReservation{HWAddress: h.hwAddress, IPAddress: h.ip, Hostname: h.name})
+		}
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteDnsmasq writes the subnet as dnsmasq dhcp-range, dhcp-option and dhcp-host configuration. Host
+// allocations without a hardware address are matched by the name the client sends.
+func (s *Subnet) WriteDnsmasq(w io.Writer, opts DHCPOptions) error {
+	scope, err := s.dhcpScope(opts)
+	if err != nil {
+		return err
+	}
+
+	tag := opts.Tag
+	if tag == "" {
+		tag = dnsmasqTag.ReplaceAllString(s.Reservation(), "-")
+	}
+	lease := ""
+	if opts.LeaseTime > 0 {
+		lease = fmt.Sprintf(",%d", int(opts.LeaseTime.Seconds()))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s %s\n", s.Reservation(), s.CIDR())
+	for _, p := range scope.pools {
+		fmt.Fprintf(&b, "dhcp-range=set:%s,%s,%s,%s%s\n", tag, inetNToA(p[0]), inetNToA(p[1]), inetNToA(inetSubnetNetmask(s.Size())), lease)
+	}
+	if scope.gateway != "" {
+		fmt.Fprintf(&b, "dhcp-option=tag:%s,option:router,%s\n", tag, scope.gateway)
+	}
+	if len(scope.dns) > 0 {
+		fmt.Fprintf(&b, "dhcp-option=tag:%s,option:dns-server,%s\n", tag, strings.Join(scope.dns, ","))
+	}
+	for _, h := range scope.hosts {
+		if h.hwAddress != "" {
+			fmt.Fprintf(&b, "dhcp-host=%s,%s,%s\n", h.hwAddress, h.ip, h.name)
+		} else {
+			fmt.Fprintf(&b, "dhcp-host=%s,%s\n", h.name, h.ip)
+		}
+	}
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+var dnsmasqTag = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// dhcpScope validates the options against the reserved subnet and resolves the pools and host allocations
+func (s *Subnet) dhcpScope(opts DHCPOptions) (*dhcpScope, error) {
+	if !s.reserved {
+		return nil, &SubnetError{CIDR: s.CIDR(), Err: ErrNotReserved}
+	}
+	if s.Size() > 30 {
+		return nil, fmt.Errorf("%w: %s has no room for a dynamic pool", ErrInvalidDHCPOptions, s.CIDR())
+	}
+
+	first, last := s.network()+1, s.broadcast()-1
+	scope := &dhcpScope{}
+	var excluded [][2]int
+
+	if opts.GatewayOffset != 0 {
+		gw := s.network() + opts.GatewayOffset
+		if opts.GatewayOffset < 0 || gw > last {
+			return nil, fmt.Errorf("%w: gateway offset %d is outside %s", ErrInvalidDHCPOptions, opts.GatewayOffset, s.CIDR())
+		}
+		scope.gateway = inetNToA(gw)
+		excluded = append(excluded, [2]int{gw, gw})
+	}
+
+	for _, dns := range opts.DNSServers {
+		if _, err := toIP(dns); err != nil {
+			return nil, fmt.Errorf("%w: DNS server: %w", ErrInvalidDHCPOptions, err)
+		}
+		scope.dns = append(scope.dns, dns)
+	}
+
+	if opts.ExcludeStart != "" {
+		end := opts.ExcludeEnd
+		if end == "" {
+			end = opts.ExcludeStart
+		}
+		from, err := toIP(opts.ExcludeStart)
+		if err != nil {
+			return nil, fmt.Errorf("%w: exclusion: %w", ErrInvalidDHCPOptions, err)
+		}
+		to, err := toIP(end)
+		if err != nil {
+			return nil, fmt.Errorf("%w: exclusion: %w", ErrInvalidDHCPOptions, err)
+		}
+		if from > to || !s.contains(from) || !s.contains(to) {
+			return nil, fmt.Errorf("%w: exclusion %s-%s is not a range within %s", ErrInvalidDHCPOptions, opts.ExcludeStart, end, s.CIDR())
+		}
+		excluded = append(excluded, [2]int{from, to})
+	}
+
+	scope.pools = subtractRanges([2]int{first, last}, excluded)
+	if len(scope.pools) == 0 {
+		return nil, fmt.Errorf("%w: no addresses left for a dynamic pool in %s", ErrInvalidDHCPOptions, s.CIDR())
+	}
+
+	for host := range s.Reserved() {
+		if host != s && host.Size() == 32 {
+			scope.hosts = append(scope.hosts, dhcpHost{
+				name:      host.Reservation(),
+				ip:        inetNToA(host.network()),
+				hwAddress: opts.HardwareAddresses[host.Reservation()],
+			})
+		}
+	}
+
+	return scope, nil
+}
+
+// subtractRanges returns the parts of the inclusive address range r not covered by any of the excluded ranges
+func subtractRanges(r [2]int, excluded [][2]int) [][2]int {
+	res := [][2]int{r}
+	for _, ex := range excluded {
+		var next [][2]int
+		for _, cur := range res {
+			if ex[1] < cur[0] || ex[0] > cur[1] {
+				next = append(next, cur)
+				continue
+			}
+			if ex[0] > cur[0] {
+				next = append(next, [2]int{cur[0], ex[0] - 1})
+			}
+			if ex[1] < cur[1] {
+				next = append(next, [2]int{ex[1] + 1, cur[1]})
+			}
+		}
+		res = next
+	}
+	return res
+}
@@ -0,0 +1,121 @@
+package subnetcalc
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WriteKea(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	lan, err := s.AddReservation("10.0.2.0/24", "office lan")
+	assert.NoError(t, err)
+	_, err = lan.AddReservation("10.0.2.10/32", "printer")
+	assert.NoError(t, err)
+	_, err = lan.AddReservation("10.0.2.11/32", "nas")
+	assert.NoError(t, err)
+
+	var b bytes.Buffer
+	assert.NoError(t, lan.WriteKea(&b, DHCPOptions{
+		GatewayOffset:     1,
+		DNSServers:        []string{"10.0.0.53", "10.0.1.53"},
+		ExcludeStart:      "10.0.2.200",
+		ExcludeEnd:        "10.0.2.254",
+		LeaseTime:         time.Hour,
+		ID:                2,
+		HardwareAddresses: map[string]string{"printer": "00:11:22:33:44:55"},
+	}))
+	assert.JSONEq(t, `{
+		"id": 2,
+		"subnet": "10.0.2.0/24",
+		"pools": [{"pool": "10.0.2.2 - 10.0.2.199"}],
+		"option-data": [
+			{"name": "routers", "data": "10.0.2.1"},
+			{"name": "domain-name-servers", "data": "10.0.0.53, 10.0.1.53"}
+		],
+		"valid-lifetime": 3600,
+		"reservations": [{"hw-address": "00:11:22:33:44:55", "ip-address": "10.0.2.10", "hostname": "printer"}],
+		"user-context": {"reservation": "office lan"}
+	}`, b.String())
+
+	b.Reset()
+	assert.NoError(t, lan.WriteKea(&b, DHCPOptions{}))
+	assert.JSONEq(t, `{
+		"subnet": "10.0.2.0/24",
+		"pools": [{"pool": "10.0.2.1 - 10.0.2.254"}],
+		"user-context": {"reservation": "office lan"}
+	}`, b.String())
+}
+
+func Test_WriteDnsmasq(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	lan, err := s.AddReservation("10.0.2.0/24", "office lan")
+	assert.NoError(t, err)
+	_, err = lan.AddReservation("10.0.2.10/32", "printer")
+	assert.NoError(t, err)
+	_, err = lan.AddReservation("10.0.2.11/32", "nas")
+	assert.NoError(t, err)
+
+	opts := DHCPOptions{
+		GatewayOffset:     1,
+		DNSServers:        []string{"10.0.0.53", "10.0.1.53"},
+		ExcludeStart:      "10.0.2.100",
+		ExcludeEnd:        "10.0.2.149",
+		LeaseTime:         time.Hour,
+		ID:                2,
+		HardwareAddresses: map[string]string{"printer": "00:11:22:33:44:55"},
+	}
+
+	var b bytes.Buffer
+	assert.NoError(t, lan.WriteDnsmasq(&b, opts))
+	assert.Equal(t, `# office lan 10.0.2.0/24
+dhcp-range=set:office-lan,10.0.2.2,10.0.2.99,255.255.255.0,3600
+dhcp-range=set:office-lan,10.0.2.150,10.0.2.254,255.255.255.0,3600
+dhcp-option=tag:office-lan,option:router,10.0.2.1
+dhcp-option=tag:office-lan,option:dns-server,10.0.0.53,10.0.1.53
+dhcp-host=00:11:22:33:44:55,10.0.2.10,printer
+dhcp-host=nas,10.0.2.11
+`, b.String())
+
+	b.Reset()
+	assert.NoError(t, lan.WriteDnsmasq(&b, DHCPOptions{Tag: "lan", GatewayOffset: 254}))
+	assert.Equal(t, `# office lan 10.0.2.0/24
+dhcp-range=set:lan,10.0.2.1,10.0.2.253,255.255.255.0
+dhcp-option=tag:lan,option:router,10.0.2.254
+dhcp-host=printer,10.0.2.10
+dhcp-host=nas,10.0.2.11
+`, b.String())
+}
+
+func Test_DHCPOptionsInvalid(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	lan, err := s.AddReservation("10.0.2.0/24", "office lan")
+	assert.NoError(t, err)
+	_, err = lan.AddReservation("10.0.2.10/32", "printer")
+	assert.NoError(t, err)
+	_, err = lan.AddReservation("10.0.2.11/32", "nas")
+	assert.NoError(t, err)
+	var b bytes.Buffer
+
+	for name, opts := range map[string]DHCPOptions{
+		"Gateway":           {GatewayOffset: 255},
+		"DNS":               {DNSServers: []string{"dns.example.com"}},
+		"ExclusionOutside":  {ExcludeStart: "10.0.3.1"},
+		"ExclusionReversed": {ExcludeStart: "10.0.2.20", ExcludeEnd: "10.0.2.10"},
+		"NoPool":            {ExcludeStart: "10.0.2.1", ExcludeEnd: "10.0.2.254"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, lan.WriteKea(&b, opts), ErrInvalidDHCPOptions)
+			assert.ErrorIs(t, lan.WriteDnsmasq(&b, opts), ErrInvalidDHCPOptions)
+		})
+	}
+
+	t.Run("NotReserved", func(t *testing.T) {
+		assert.ErrorIs(t, lan.parent().WriteKea(&b, DHCPOptions{}), ErrNotReserved)
+	})
+}
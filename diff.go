@@ -0,0 +1,135 @@
+package subnetcalc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrRootMismatch = errors.New("subnet trees have different roots")
+
+// Change describes a reservation that differs between two subnet trees
+type Change struct {
+	CIDR    string `json:"cidr"`
+	Name    string `json:"name"`
+	OldCIDR string `json:"old_cidr,omitempty"`
+	OldName string `json:"old_name,omitempty"`
+}
+
+// Changes lists the reservation differences between two subnet trees
+type Changes struct {
+	Added   []Change `json:"added"`
+	Removed []Change `json:"removed"`
+	Renamed []Change `json:"renamed"`
+	Resized []Change `json:"resized"`
+}
+
+// Diff compares the reservations of two trees of the same root. Reservations keeping their CIDR under a new
+// name are reported as renamed, and reservations keeping their name on an overlapping CIDR as resized.
+func Diff(a, b *Subnet) (*Changes, error) {
+	if a.CIDR() != b.CIDR() {
+		return nil, fmt.Errorf("%w: %s and %s", ErrRootMismatch, a.CIDR(), b.CIDR())
+	}
+
+	old := a.sortedReservations()
+	cur := b.sortedReservations()
+
+	// Unchanged reservations present in both trees
+	inOld, inCur := reservationKeys(old), reservationKeys(cur)
+	old = withoutUnchanged(old, inCur)
+	cur = withoutUnchanged(cur, inOld)
+
+	changes := &Changes{
+		Added:   []Change{},
+		Removed: []Change{},
+		Renamed: []Change{},
+		Resized: []Change{},
+	}
+
+	// Same CIDR with another name
+	matched := map[*Subnet]bool{}
+	for _, n := range cur {
+		for _, o := range old {
+			if !matched[o] && o.CIDR() == n.CIDR() {
+				matched[o], matched[n] = true, true
+				changes.Renamed = append(changes.Renamed, Change{CIDR: n.CIDR(), Name: n.Reservation(), OldName: o.Reservation()})
+				break
+			}
+		}
+	}
+
+	// Same name on an overlapping CIDR
+	for _, n := range cur {
+		if matched[n] {
+			continue
+		}
+		for _, o := range old {
+			if !matched[o] && o.Reservation() == n.Reservation() && o.overlaps(n) {
+				matched[o], matched[n] = true, true
+				changes.Resized = append(changes.Resized, Change{CIDR: n.CIDR(), Name: n.Reservation(), OldCIDR: o.CIDR()})
+				break
+			}
+		}
+	}
+
+	for _, n := range cur {
+		if !matched[n] {
+			changes.Added = append(changes.Added, Change{CIDR: n.CIDR(), Name: n.Reservation()})
+		}
+	}
+	for _, o := range old {
+		if !matched[o] {
+			changes.Removed = append(changes.Removed, Change{CIDR: o.CIDR(), Name: o.Reservation()})
+		}
+	}
+
+	return changes, nil
+}
+
+// Empty is true if there are no changes
+func (c *Changes) Empty() bool {
+	return len(c.Added)+len(c.Removed)+len(c.Renamed)+len(c.Resized) == 0
+}
+
+// String renders the changes in a human-readable form, one change per line
+func (c *Changes) String() string {
+	var b strings.Builder
+	for _, ch := range c.Added {
+		fmt.Fprintf(&b, "+ %s %q\n", ch.CIDR, ch.Name)
+	}
+	for _, ch := range c.Removed {
+		fmt.Fprintf(&b, "- %s %q\n", ch.CIDR, ch.Name)
+	}
+	for _, ch := range c.Renamed {
+		fmt.Fprintf(&b, "~ %s renamed %q -> %q\n", ch.CIDR, ch.OldName, ch.Name)
+	}
+	for _, ch := range c.Resized {
+		fmt.Fprintf(&b, "~ %q resized %s -> %s\n", ch.Name, ch.OldCIDR, ch.CIDR)
+	}
+	return b.String()
+}
+
+func reservationKey(s *Subnet) string {
+	return s.CIDR() + "\x00" + s.Reservation()
+}
+
+func reservationKeys(subnets []*Subnet) map[string]int {
+	keys := map[string]int{}
+	for _, s := range subnets {
+		keys[reservationKey(s)]++
+	}
+	return keys
+}
+
+// withoutUnchanged drops the subnets having a matching reservation in the other tree
+func withoutUnchanged(subnets []*Subnet, other map[string]int) []*Subnet {
+	var res []*Subnet
+	for _, s := range subnets {
+		if key := reservationKey(s); other[key] > 0 {
+			other[key]--
+			continue
+		}
+		res = append(res, s)
+	}
+	return res
+}
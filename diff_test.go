@@ -0,0 +1,89 @@
+package subnetcalc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Diff(t *testing.T) {
+	t.Run("Changes", func(t *testing.T) {
+		a, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		b, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		for cidr, name := range map[string]string{"10.0.0.0/24": "same", "10.0.1.0/24": "old-name", "10.0.2.0/25": "grows", "10.0.8.0/24": "gone"} {
+			_, err = a.AddReservation(cidr, name)
+			assert.NoError(t, err)
+		}
+		for cidr, name := range map[string]string{"10.0.0.0/24": "same", "10.0.1.0/24": "new-name", "10.0.2.0/24": "grows", "10.0.9.0/24": "new"} {
+			_, err = b.AddReservation(cidr, name)
+			assert.NoError(t, err)
+		}
+
+		changes, err := Diff(a, b)
+		assert.NoError(t, err)
+		assert.False(t, changes.Empty())
+		assert.Equal(t, []Change{{CIDR: "10.0.9.0/24", Name: "new"}}, changes.Added)
+		assert.Equal(t, []Change{{CIDR: "10.0.8.0/24", Name: "gone"}}, changes.Removed)
+		assert.Equal(t, []Change{{CIDR: "10.0.1.0/24", Name: "new-name", OldName: "old-name"}}, changes.Renamed)
+		assert.Equal(t, []Change{{CIDR: "10.0.2.0/24", Name: "grows", OldCIDR: "10.0.2.0/25"}}, changes.Resized)
+
+		assert.Equal(t, `+ 10.0.9.0/24 "new"
+- 10.0.8.0/24 "gone"
+~ 10.0.1.0/24 renamed "old-name" -> "new-name"
+~ "grows" resized 10.0.2.0/25 -> 10.0.2.0/24
+`, changes.String())
+
+		data, err := json.Marshal(changes)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"added": [{"cidr": "10.0.9.0/24", "name": "new"}],
+			"removed": [{"cidr": "10.0.8.0/24", "name": "gone"}],
+			"renamed": [{"cidr": "10.0.1.0/24", "name": "new-name", "old_name": "old-name"}],
+			"resized": [{"cidr": "10.0.2.0/24", "name": "grows", "old_cidr": "10.0.2.0/25"}]
+		}`, string(data))
+	})
+
+	t.Run("Moved is added and removed", func(t *testing.T) {
+		a, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		b, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		_, err = a.AddReservation("10.0.0.0/24", "app")
+		assert.NoError(t, err)
+		_, err = b.AddReservation("10.0.5.0/24", "app")
+		assert.NoError(t, err)
+
+		changes, err := Diff(a, b)
+		assert.NoError(t, err)
+		assert.Len(t, changes.Added, 1)
+		assert.Len(t, changes.Removed, 1)
+		assert.Empty(t, changes.Resized)
+	})
+
+	t.Run("No changes", func(t *testing.T) {
+		a, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		_, err = a.AddReservation("10.0.0.0/24", "app")
+		assert.NoError(t, err)
+
+		changes, err := Diff(a, a)
+		assert.NoError(t, err)
+		assert.True(t, changes.Empty())
+		assert.Equal(t, "", changes.String())
+	})
+
+	t.Run("Different roots", func(t *testing.T) {
+		a, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		b, err := Parse("10.1.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		_, err = Diff(a, b)
+		assert.ErrorIs(t, err, ErrRootMismatch)
+	})
+}
@@ -0,0 +1,77 @@
+package subnetcalc
+
+import (
+	"fmt"
+)
+
+// ParseError is returned when a CIDR or IP address cannot be parsed, and matches ErrCouldNotParse
+type ParseError struct {
+	Input string
+	Err   error // Reason reported by the net package
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v %q: %v", ErrCouldNotParse, e.Input, e.Err)
+}
+
+func (e *ParseError) Is(target error) bool {
+	return target == ErrCouldNotParse
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ConflictError is returned when a requested reservation conflicts with an existing one, and matches
+// ErrAlreadyReserved
+type ConflictError struct {
+	CIDR        string  // Requested subnet
+	Name        string  // Requested reservation name
+	Conflict    *Subnet // Reserved subnet in the way
+	Reservation string  // Reservation name of the conflicting subnet at the time of the conflict
+}
+
+func (e *ConflictError) Error() string {
+	if e.Conflict.CIDR() == e.CIDR {
+		return fmt.Sprintf("%v: %s requested as %q is reserved as %q", ErrAlreadyReserved, e.CIDR, e.Name, e.Reservation)
+	}
+	return fmt.Sprintf("%v: %s requested as %q overlaps %s reserved as %q", ErrAlreadyReserved, e.CIDR, e.Name, e.Conflict.CIDR(), e.Reservation)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrAlreadyReserved
+}
+
+// NotFoundError is returned when a requested subnet is outside the tree or no free subnet is available, and
+// matches ErrDidNotFindSubnet
+type NotFoundError struct {
+	Root string // Subnet searched
+	CIDR string // Requested CIDR or address, if any
+	Size int    // Requested size, if any
+}
+
+func (e *NotFoundError) Error() string {
+	if e.CIDR != "" {
+		return fmt.Sprintf("%v: %s is not within %s", ErrDidNotFindSubnet, e.CIDR, e.Root)
+	}
+	return fmt.Sprintf("%v: no free /%d in %s", ErrDidNotFindSubnet, e.Size, e.Root)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrDidNotFindSubnet
+}
+
+// SubnetError is returned when an operation is not possible in the current state of a subnet, and matches the
+// wrapped error, e.g. ErrNotReserved
+type SubnetError struct {
+	CIDR string
+	Err  error
+}
+
+func (e *SubnetError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Err, e.CIDR)
+}
+
+func (e *SubnetError) Unwrap() error {
+	return e.Err
+}
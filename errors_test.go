@@ -0,0 +1,68 @@
+package subnetcalc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseError(t *testing.T) {
+	_, err := Parse("10.0.0.0/40")
+	assert.ErrorIs(t, err, ErrCouldNotParse)
+	assert.EqualError(t, err, `could not parse subnet specification "10.0.0.0/40": invalid CIDR address: 10.0.0.0/40`)
+
+	var parseErr *ParseError
+	if assert.True(t, errors.As(err, &parseErr)) {
+		assert.Equal(t, "10.0.0.0/40", parseErr.Input)
+		assert.Error(t, parseErr.Err)
+	}
+}
+
+func Test_ConflictError(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(t, err)
+
+	_, err = s.AddReservation("10.0.0.0/24", "db")
+	assert.ErrorIs(t, err, ErrAlreadyReserved)
+	assert.EqualError(t, err, `subnet is already reserved: 10.0.0.0/24 requested as "db" is reserved as "web"`)
+
+	var conflict *ConflictError
+	if assert.True(t, errors.As(err, &conflict)) {
+		assert.Equal(t, "10.0.0.0/24", conflict.CIDR)
+		assert.Equal(t, "db", conflict.Name)
+		assert.Equal(t, "web", conflict.Reservation)
+		assert.Equal(t, "10.0.0.0/24", conflict.Conflict.CIDR())
+	}
+}
+
+func Test_NotFoundError(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	_, err = s.AddReservation("192.168.0.0/24", "outside")
+	assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+	assert.EqualError(t, err, "could not find suitable subnet: 192.168.0.0/24 is not within 10.0.0.0/16")
+
+	_, err = s.FindFree(8)
+	assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+	assert.EqualError(t, err, "could not find suitable subnet: no free /8 in 10.0.0.0/16")
+
+	var notFound *NotFoundError
+	if assert.True(t, errors.As(err, &notFound)) {
+		assert.Equal(t, 8, notFound.Size)
+		assert.Equal(t, "10.0.0.0/16", notFound.Root)
+	}
+}
+
+func Test_SubnetError(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	err = s.UnReserve()
+	assert.ErrorIs(t, err, ErrNotReserved)
+	assert.EqualError(t, err, "subnet is not reserved: 10.0.0.0/16")
+}
@@ -0,0 +1,90 @@
+package subnetcalc
+
+import (
+	"fmt"
+)
+
+// EventType identifies the kind of change an Event describes
+type EventType int
+
+const (
+	EventReserved    EventType = iota + 1 // Subnet was reserved
+	EventUnreserved                       // Reservation of the subnet was removed
+	EventRenamed                          // Reservation of the subnet changed name
+	EventPoolChanged                      // Subnet was marked or unmarked as a pool
+	EventDivided                          // Reservation of the subnet was shrunk into a child subnet
+	EventMerged                           // Reservation of a child subnet was grown into the subnet
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventReserved:
+		return "reserved"
+	case EventUnreserved:
+		return "unreserved"
+	case EventRenamed:
+		return "renamed"
+	case EventPoolChanged:
+		return "pool changed"
+	case EventDivided:
+		return "divided"
+	case EventMerged:
+		return "merged"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event describes a change to a subnet in the tree
+type Event struct {
+	Type    EventType
+	Subnet  *Subnet
+	Path    []string // CIDRs from the root of the tree down to the subnet
+	Name    string   // Reservation name after the change
+	OldName string   // Reservation name before the change, for renamed and unreserved subnets
+	OldCIDR string   // Subnet the reservation was resized from, for merged and divided subnets
+	Pool    bool     // Pool flag after the change
+	OldPool bool     // Pool flag before the change, for unreserved subnets
+}
+
+// Observer receives events on changes in a subnet tree
+type Observer interface {
+	Notify(e Event)
+}
+
+// ObserverFunc allows an ordinary function to be used as an Observer
+type ObserverFunc func(e Event)
+
+// Notify calls f(e)
+func (f ObserverFunc) Notify(e Event) {
+	f(e)
+}
+
+// AddObserver registers observers on the tree. Observers are notified synchronously, in order of registration,
+// after each change has been made.
+func (s *Subnet) AddObserver(observers ...Observer) {
+	st := s.state()
+	st.observers = append(st.observers, observers...)
+}
+
+// Path returns the CIDRs from the root of the tree down to the subnet
+func (s *Subnet) Path() []string {
+	path := make([]string, s.depth()+1)
+	for i, n := len(path)-1, s; n != nil; i, n = i-1, n.parent() {
+		path[i] = n.CIDR()
+	}
+	return path
+}
+
+// notify sends the event for the subnet to the observers of the tree
+func (s *Subnet) notify(e Event) {
+	st := (*tree)(s.root().up)
+	if st == nil || len(st.observers) == 0 {
+		return
+	}
+
+	e.Subnet = s
+	e.Path = s.Path()
+	for _, o := range st.observers {
+		o.Notify(e)
+	}
+}
@@ -0,0 +1,143 @@
+package subnetcalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) Notify(e Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) types() []EventType {
+	var res []EventType
+	for _, e := range r.events {
+		res = append(res, e.Type)
+	}
+	return res
+}
+
+func Test_Events(t *testing.T) {
+	t.Run("Reservations", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		rec := &recorder{}
+		s.AddObserver(rec)
+
+		sn, err := s.FindFreeAndReserve(24, "web")
+		assert.NoError(t, err)
+		assert.NoError(t, sn.Rename("www"))
+		assert.NoError(t, sn.SetPool(true))
+		assert.NoError(t, sn.SetPool(true))
+		assert.NoError(t, sn.SetPool(false))
+		assert.NoError(t, sn.UnReserve())
+
+		assert.Equal(t, []EventType{EventReserved, EventRenamed, EventPoolChanged, EventPoolChanged, EventUnreserved}, rec.types())
+
+		assert.Equal(t, Event{
+			Type:   EventReserved,
+			Subnet: sn,
+			Path:   []string{"10.0.0.0/16", "10.0.0.0/17", "10.0.0.0/18", "10.0.0.0/19", "10.0.0.0/20", "10.0.0.0/21", "10.0.0.0/22", "10.0.0.0/23", "10.0.0.0/24"},
+			Name:   "web",
+		}, rec.events[0])
+		assert.Equal(t, "www", rec.events[1].Name)
+		assert.Equal(t, "web", rec.events[1].OldName)
+		assert.True(t, rec.events[2].Pool)
+		assert.Equal(t, "www", rec.events[4].OldName)
+		assert.Equal(t, "", rec.events[4].Name)
+	})
+
+	t.Run("Unreserved pool", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.16.0/20", "team")
+		assert.NoError(t, err)
+		assert.NoError(t, sn.SetPool(true))
+
+		rec := &recorder{}
+		s.AddObserver(rec)
+		assert.NoError(t, sn.UnReserve())
+
+		if assert.Len(t, rec.events, 1) {
+			assert.Equal(t, "team", rec.events[0].OldName)
+			assert.True(t, rec.events[0].OldPool)
+			assert.False(t, rec.events[0].Pool)
+		}
+	})
+
+	t.Run("Divided", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/24")
+		assert.NoError(t, err, "parse should return no error")
+
+		rec := &recorder{}
+		s.AddObserver(rec)
+
+		_, err = s.AddReservation("10.0.0.5/32", "host")
+		assert.NoError(t, err)
+		_, err = s.FindFree(30)
+		assert.NoError(t, err)
+		_, err = s.FindFreeDeterministic(29, "other")
+		assert.NoError(t, err)
+		assert.Equal(t, []EventType{EventReserved}, rec.types(), "dividing subnets while searching is not observed")
+
+		sn, err := s.AddReservation("10.0.0.64/26", "team")
+		assert.NoError(t, err)
+		_, err = sn.Shrink(28)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []EventType{EventReserved, EventReserved, EventUnreserved, EventReserved, EventDivided}, rec.types())
+		assert.Equal(t, "10.0.0.64/28", rec.events[4].Subnet.CIDR())
+		assert.Equal(t, "10.0.0.64/26", rec.events[4].OldCIDR)
+		assert.Equal(t, "team", rec.events[4].Name)
+	})
+
+	t.Run("Merged", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.1.0/25", "team")
+		assert.NoError(t, err)
+
+		var types []EventType
+		s.AddObserver(ObserverFunc(func(e Event) {
+			types = append(types, e.Type)
+			if e.Type == EventMerged {
+				assert.Equal(t, "10.0.1.0/24", e.Subnet.CIDR())
+				assert.Equal(t, "10.0.1.0/25", e.OldCIDR)
+				assert.Equal(t, "team", e.Name)
+			}
+		}))
+
+		_, err = sn.Grow()
+		assert.NoError(t, err)
+		assert.Equal(t, []EventType{EventUnreserved, EventReserved, EventMerged}, types)
+	})
+
+	t.Run("Failures are not reported", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		rec := &recorder{}
+		s.AddObserver(rec)
+
+		_, err = s.AddReservation("10.0.0.0/24", "web")
+		assert.NoError(t, err)
+		_, err = s.AddReservation("10.0.0.0/24", "other")
+		assert.Error(t, err)
+		assert.Error(t, s.UnReserve())
+
+		assert.Equal(t, []EventType{EventReserved}, rec.types())
+	})
+}
+
+func Test_EventTypeString(t *testing.T) {
+	assert.Equal(t, "pool changed", EventPoolChanged.String())
+	assert.Equal(t, "EventType(42)", EventType(42).String())
+}
@@ -0,0 +1,150 @@
+package subnetcalc
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrDuplicateName = errors.New("reservation name is not unique")
+
+type exportRecord struct {
+	Name    string `yaml:"name"`
+	CIDR    string `yaml:"cidr"`
+	FirstIP string `yaml:"first_ip"`
+	LastIP  string `yaml:"last_ip"`
+	Size    int    `yaml:"size"`
+}
+
+// ExportCSV writes all reservations as CSV records with the columns cidr,name,first_ip,last_ip,size
+func (s *Subnet) ExportCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"cidr", "name", "first_ip", "last_ip", "size"}); err != nil {
+		return err
+	}
+
+	for _, r := range s.exportRecords() {
+		if err := cw.Write([]string{r.CIDR, r.Name, r.FirstIP, r.LastIP, strconv.Itoa(r.Size)}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportYAML writes all reservations as a YAML document with a list of reservations
+func (s *Subnet) ExportYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	doc := struct {
+		Network      string         `yaml:"network"`
+		Reservations []exportRecord `yaml:"reservations"`
+	}{
+		Network:      s.CIDR(),
+		Reservations: s.exportRecords(),
+	}
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// ExportTFVars writes all reservations as a Terraform variable assignment of a map from reservation name
+// to CIDR, first and last IP and size. Reservation names must be unique to be used as map keys.
+func (s *Subnet) ExportTFVars(w io.Writer, variable string) error {
+	records := s.exportRecords()
+
+	seen := map[string]bool{}
+	for _, r := range records {
+		if seen[r.Name] {
+			return fmt.Errorf("%w: %q", ErrDuplicateName, r.Name)
+		}
+		seen[r.Name] = true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s = {\n", variable)
+	for _, r := range records {
+		fmt.Fprintf(&b, "  %s = {\n", hclString(r.Name))
+		fmt.Fprintf(&b, "    cidr     = %s\n", hclString(r.CIDR))
+		fmt.Fprintf(&b, "    first_ip = %s\n", hclString(r.FirstIP))
+		fmt.Fprintf(&b, "    last_ip  = %s\n", hclString(r.LastIP))
+		fmt.Fprintf(&b, "    size     = %d\n", r.Size)
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sortedReservations returns all reservations ordered by address, with larger subnets before the ones they contain
+func (s *Subnet) sortedReservations() []*Subnet {
+	reserved := slices.Collect(s.Reserved())
+	sort.SliceStable(reserved, func(i, j int) bool {
+		if reserved[i].network() != reserved[j].network() {
+			return reserved[i].network() < reserved[j].network()
+		}
+		return reserved[i].Size() < reserved[j].Size()
+	})
+	return reserved
+}
+
+// exportRecords returns all reservations in address order
+func (s *Subnet) exportRecords() []exportRecord {
+	reserved := s.sortedReservations()
+	records := make([]exportRecord, 0, len(reserved))
+	for _, r := range reserved {
+		records = append(records, exportRecord{
+			Name:    r.Reservation(),
+			CIDR:    r.CIDR(),
+			FirstIP: r.FirstIP(),
+			LastIP:  r.LastIP(),
+			Size:    r.Size(),
+		})
+	}
+	return records
+}
+
+// hclString quotes a string as a HCL literal. HCL only has the escapes \n, \r, \t, \", \\, \uNNNN and
+// \UNNNNNNNN, and template sequences must be escaped as $${ and %%{.
+func hclString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case (r == '{') && i > 0 && (s[i-1] == '$' || s[i-1] == '%'):
+			b.WriteByte(s[i-1])
+			b.WriteRune(r)
+		case r > 0xffff && !unicode.IsPrint(r):
+			fmt.Fprintf(&b, `\U%08X`, r)
+		case !unicode.IsPrint(r):
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package subnetcalc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExportCSV(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = s.AddReservation("10.0.1.0/24", "db")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.0.0/28", "web-lb")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.ExportCSV(&buf))
+	assert.Equal(t, `cidr,name,first_ip,last_ip,size
+10.0.0.0/24,web,10.0.0.1,10.0.0.254,24
+10.0.0.0/28,web-lb,10.0.0.1,10.0.0.14,28
+10.0.1.0/24,db,10.0.1.1,10.0.1.254,24
+`, buf.String())
+}
+
+func Test_ExportYAML(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = s.AddReservation("10.0.1.0/24", "db")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.0.0/28", "web-lb")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, s.ExportYAML(&buf))
+	assert.Equal(t, `network: 10.0.0.0/16
+reservations:
+  - name: web
+    cidr: 10.0.0.0/24
+    first_ip: 10.0.0.1
+    last_ip: 10.0.0.254
+    size: 24
+  - name: web-lb
+    cidr: 10.0.0.0/28
+    first_ip: 10.0.0.1
+    last_ip: 10.0.0.14
+    size: 28
+  - name: db
+    cidr: 10.0.1.0/24
+    first_ip: 10.0.1.1
+    last_ip: 10.0.1.254
+    size: 24
+`, buf.String())
+}
+
+func Test_ExportTFVars(t *testing.T) {
+	t.Run("Ok", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		_, err = s.AddReservation("10.0.2.0/23", "app ${env}")
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		assert.NoError(t, s.ExportTFVars(&buf, "subnets"))
+		assert.Equal(t, `subnets = {
+  "app $${env}" = {
+    cidr     = "10.0.2.0/23"
+    first_ip = "10.0.2.1"
+    last_ip  = "10.0.3.254"
+    size     = 23
+  }
+}
+`, buf.String())
+	})
+
+	t.Run("Escapes", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		_, err = s.AddReservation("10.0.0.0/24", "a\"b\\c\n\t\x01\a\u2028 %{x} $$y æ")
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		assert.NoError(t, s.ExportTFVars(&buf, "subnets"))
+		assert.Contains(t, buf.String(), `  "a\"b\\c\n\t\u0001\u0007\u2028 %%{x} $$y æ" = {`)
+	})
+
+	t.Run("Duplicate names", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		_, err = s.AddReservation("10.0.0.0/24", "test")
+		assert.NoError(t, err)
+		_, err = s.AddReservation("10.0.1.0/24", "test")
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		assert.ErrorIs(t, s.ExportTFVars(&buf, "subnets"), ErrDuplicateName)
+		assert.Empty(t, buf.String())
+	})
+}
@@ -1,11 +1,16 @@
 module github.com/kschjeld/subnetcalc
 
-go 1.19
+go 1.23
 
-require github.com/stretchr/testify v1.8.1
+require (
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/stretchr/testify v1.10.0
+	go.etcd.io/bbolt v1.4.3
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/sys v0.29.0 // indirect
 )
@@ -0,0 +1,184 @@
+package subnetcalc
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ImportResult reports the outcome of a bulk import
+type ImportResult struct {
+	Imported  []*Subnet
+	Conflicts []ImportConflict
+}
+
+// ImportConflict describes a single record that could not be imported
+type ImportConflict struct {
+	Record string // Line number, NetBox id or Terraform address of the record
+	CIDR   string
+	Name   string
+	Err    error
+}
+
+func (c ImportConflict) Error() string {
+	return fmt.Sprintf("%s: %s (%s): %v", c.Record, c.CIDR, c.Name, c.Err)
+}
+
+func (c ImportConflict) Unwrap() error {
+	return c.Err
+}
+
+// ImportCSV adds reservations from CSV records on the form cidr,name,... Additional columns are ignored,
+// and a leading header row starting with "cidr" is skipped. Records failing to import are reported as
+// conflicts in the result, while malformed CSV aborts the import.
+func (s *Subnet) ImportCSV(r io.Reader) (*ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	res := &ImportResult{}
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return res, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), "cidr") {
+			continue
+		}
+
+		var name string
+		if len(record) > 1 {
+			name = strings.TrimSpace(record[1])
+		}
+		s.importRecord(res, fmt.Sprintf("line %d", line), strings.TrimSpace(record[0]), name)
+	}
+
+	return res, nil
+}
+
+type netBoxPrefix struct {
+	ID          int    `json:"id"`
+	Prefix      string `json:"prefix"`
+	Description string `json:"description"`
+}
+
+// ImportNetBox adds reservations from a NetBox prefix export, either the paginated API response or a plain
+// JSON list of prefixes. The prefix description is used as reservation name.
+func (s *Subnet) ImportNetBox(r io.Reader) (*ImportResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixes []netBoxPrefix
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &prefixes)
+	} else {
+		var page struct {
+			Results []netBoxPrefix `json:"results"`
+		}
+		err = json.Unmarshal(data, &page)
+		prefixes = page.Results
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &ImportResult{}
+	for i, p := range prefixes {
+		record := fmt.Sprintf("prefix %d", i)
+		if p.ID != 0 {
+			record = fmt.Sprintf("prefix id %d", p.ID)
+		}
+
+		s.importRecord(res, record, p.Prefix, p.Description)
+	}
+
+	return res, nil
+}
+
+type terraformState struct {
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   interface{} `json:"index_key"`
+			Attributes struct {
+				CIDRBlock string            `json:"cidr_block"`
+				Tags      map[string]string `json:"tags"`
+			} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// ImportTerraformState adds reservations for the aws_subnet resources found in a local Terraform state file.
+// The Name tag is used as reservation name, falling back to the resource address.
+func (s *Subnet) ImportTerraformState(path string) (*ImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var state terraformState
+	if err = json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	res := &ImportResult{}
+	for _, r := range state.Resources {
+		if r.Mode != "managed" || r.Type != "aws_subnet" {
+			continue
+		}
+
+		for _, inst := range r.Instances {
+			address := r.Type + "." + r.Name
+			if r.Module != "" {
+				address = r.Module + "." + address
+			}
+			switch key := inst.IndexKey.(type) {
+			case string:
+				address += fmt.Sprintf("[%q]", key)
+			case float64:
+				address += fmt.Sprintf("[%d]", int(key))
+			}
+
+			name := inst.Attributes.Tags["Name"]
+			if name == "" {
+				name = address
+			}
+			s.importRecord(res, address, inst.Attributes.CIDRBlock, name)
+		}
+	}
+
+	return res, nil
+}
+
+// importRecord reserves a single record, using the CIDR as name for unnamed records
+func (s *Subnet) importRecord(res *ImportResult, record, cidr, name string) {
+	if name == "" {
+		name = cidr
+	}
+
+	sn, err := s.AddReservation(cidr, name)
+	if err != nil {
+		res.Conflicts = append(res.Conflicts, ImportConflict{
+			Record: record,
+			CIDR:   cidr,
+			Name:   name,
+			Err:    err,
+		})
+		return
+	}
+	res.Imported = append(res.Imported, sn)
+}
@@ -0,0 +1,118 @@
+package subnetcalc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ImportCSV(t *testing.T) {
+	t.Run("Header and extra columns", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		res, err := s.ImportCSV(strings.NewReader("cidr,name,owner\n10.0.0.0/24,web,team-a\n10.0.1.0/24,db,team-b\n"))
+		assert.NoError(t, err)
+		assert.Len(t, res.Imported, 2)
+		assert.Empty(t, res.Conflicts)
+		assert.Equal(t, "10.0.1.0/24", res.Imported[1].CIDR())
+		assert.Equal(t, "db", res.Imported[1].Reservation())
+	})
+
+	t.Run("Conflicts are reported per row", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		res, err := s.ImportCSV(strings.NewReader("10.0.0.0/24,web\n10.0.0.0/24,other\n192.168.0.0/24,outside\nbogus,broken\n10.0.2.0/24\n"))
+		assert.NoError(t, err)
+		assert.Len(t, res.Imported, 2)
+		assert.Equal(t, "10.0.2.0/24", res.Imported[1].Reservation())
+
+		if assert.Len(t, res.Conflicts, 3) {
+			assert.Equal(t, "line 2", res.Conflicts[0].Record)
+			assert.ErrorIs(t, res.Conflicts[0], ErrAlreadyReserved)
+			assert.ErrorIs(t, res.Conflicts[1], ErrDidNotFindSubnet)
+			assert.ErrorIs(t, res.Conflicts[2], ErrCouldNotParse)
+		}
+	})
+
+	t.Run("Malformed CSV", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		_, err = s.ImportCSV(strings.NewReader("10.0.0.0/24,\"web\n"))
+		assert.Error(t, err)
+	})
+}
+
+func Test_ImportNetBox(t *testing.T) {
+	t.Run("Paginated", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		res, err := s.ImportNetBox(strings.NewReader(`{"count":2,"results":[
+			{"id":7,"prefix":"10.0.4.0/22","description":"k8s"},
+			{"id":8,"prefix":"10.0.4.0/22","description":"dup"}]}`))
+		assert.NoError(t, err)
+		assert.Len(t, res.Imported, 1)
+		assert.Equal(t, "k8s", res.Imported[0].Reservation())
+		if assert.Len(t, res.Conflicts, 1) {
+			assert.Equal(t, "prefix id 8", res.Conflicts[0].Record)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		res, err := s.ImportNetBox(strings.NewReader(`[{"prefix":"10.0.8.0/24"}]`))
+		assert.NoError(t, err)
+		assert.Len(t, res.Imported, 1)
+		assert.Equal(t, "10.0.8.0/24", res.Imported[0].Reservation())
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		_, err = s.ImportNetBox(strings.NewReader(`{"results":`))
+		assert.Error(t, err)
+	})
+}
+
+func Test_ImportTerraformState(t *testing.T) {
+	state := `{
+  "version": 4,
+  "resources": [
+    {"mode": "managed", "type": "aws_vpc", "name": "main", "instances": [{"attributes": {"cidr_block": "10.0.0.0/16"}}]},
+    {"mode": "data", "type": "aws_subnet", "name": "existing", "instances": [{"attributes": {"cidr_block": "10.0.9.0/24"}}]},
+    {"module": "module.net", "mode": "managed", "type": "aws_subnet", "name": "private", "instances": [
+      {"index_key": 0, "attributes": {"cidr_block": "10.0.0.0/24", "tags": {"Name": "private-a"}}},
+      {"index_key": 1, "attributes": {"cidr_block": "10.0.1.0/24"}},
+      {"index_key": 2, "attributes": {"cidr_block": "10.1.0.0/24"}}
+    ]}
+  ]
+}`
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	assert.NoError(t, os.WriteFile(path, []byte(state), 0o600))
+
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	res, err := s.ImportTerraformState(path)
+	assert.NoError(t, err)
+	if assert.Len(t, res.Imported, 2) {
+		assert.Equal(t, "private-a", res.Imported[0].Reservation())
+		assert.Equal(t, "module.net.aws_subnet.private[1]", res.Imported[1].Reservation())
+	}
+	if assert.Len(t, res.Conflicts, 1) {
+		assert.Equal(t, "module.net.aws_subnet.private[2]", res.Conflicts[0].Record)
+		assert.ErrorIs(t, res.Conflicts[0], ErrDidNotFindSubnet)
+	}
+
+	_, err = s.ImportTerraformState(filepath.Join(t.TempDir(), "missing.tfstate"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,65 @@
+package subnetcalc
+
+import (
+	"iter"
+)
+
+// Walk visits the subnet and its children depth first, low before high. The visit function decides whether to
+// descend into the children of the visited subnet, and whether to stop the walk altogether.
+func (s *Subnet) Walk(fn func(s *Subnet) (descend bool, stop bool)) {
+	s.walk(fn)
+}
+
+// All iterates over the subnet and all its children in the same order as Walk
+func (s *Subnet) All() iter.Seq[*Subnet] {
+	return func(yield func(*Subnet) bool) {
+		s.Walk(func(sn *Subnet) (bool, bool) {
+			return true, !yield(sn)
+		})
+	}
+}
+
+// Reserved iterates over reserved subnets, skipping subtrees without reservations
+func (s *Subnet) Reserved() iter.Seq[*Subnet] {
+	return func(yield func(*Subnet) bool) {
+		s.Walk(func(sn *Subnet) (bool, bool) {
+			if sn.reserved && !yield(sn) {
+				return false, true
+			}
+			return sn.subReservations > 0, false
+		})
+	}
+}
+
+// Available iterates over the largest free subnets, i.e. free subnets whose parent is not free. Neither
+// reserved nor free subnets are descended into.
+func (s *Subnet) Available() iter.Seq[*Subnet] {
+	return func(yield func(*Subnet) bool) {
+		s.Walk(func(sn *Subnet) (bool, bool) {
+			if sn.reserved {
+				return false, false
+			}
+			if sn.subReservations == 0 {
+				return false, !yield(sn)
+			}
+			return true, false
+		})
+	}
+}
+
+// walk does the actual Walk, returning true if the walk was stopped
+func (s *Subnet) walk(fn func(s *Subnet) (bool, bool)) bool {
+	if s == nil {
+		return false
+	}
+
+	descend, stop := fn(s)
+	if stop {
+		return true
+	}
+	if !descend {
+		return false
+	}
+
+	return s.low().walk(fn) || s.high().walk(fn)
+}
@@ -0,0 +1,159 @@
+package subnetcalc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Walk(t *testing.T) {
+	t.Run("Descend", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/30")
+		assert.NoError(t, err, "parse should return no error")
+
+		var visited []*Subnet
+		s.Walk(func(sn *Subnet) (bool, bool) {
+			visited = append(visited, sn)
+			return sn.Size() < 31, false
+		})
+		assert.Equal(t, []*Subnet{s, s.low(), s.high()}, visited)
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/24")
+		assert.NoError(t, err, "parse should return no error")
+
+		var visited []*Subnet
+		s.Walk(func(sn *Subnet) (bool, bool) {
+			visited = append(visited, sn)
+			return true, sn.Size() == 26
+		})
+		assert.Equal(t, []*Subnet{s, s.low(), s.low().low()}, visited)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		var s *Subnet
+		s.Walk(func(sn *Subnet) (bool, bool) {
+			t.Fatal("should not visit nil subnet")
+			return true, false
+		})
+	})
+}
+
+func Test_Iterators(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	web, err := s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(t, err)
+	webLB, err := s.AddReservation("10.0.0.0/28", "web-lb")
+	assert.NoError(t, err)
+	db, err := s.AddReservation("10.0.1.0/25", "db")
+	assert.NoError(t, err)
+
+	t.Run("All", func(t *testing.T) {
+		assert.Equal(t, s.Collect(), slices.Collect(s.All()))
+	})
+
+	t.Run("Reserved", func(t *testing.T) {
+		assert.Equal(t, []*Subnet{web, webLB, db}, slices.Collect(s.Reserved()))
+		assert.Equal(t, s.Collect(SelectReserved()), slices.Collect(s.Reserved()))
+	})
+
+	t.Run("Available", func(t *testing.T) {
+		var available []string
+		for sn := range s.Available() {
+			available = append(available, sn.CIDR())
+		}
+		assert.Equal(t, []string{
+			"10.0.1.128/25",
+			"10.0.2.0/23",
+			"10.0.4.0/22",
+			"10.0.8.0/21",
+			"10.0.16.0/20",
+			"10.0.32.0/19",
+			"10.0.64.0/18",
+			"10.0.128.0/17",
+		}, available)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		var first *Subnet
+		for sn := range s.Reserved() {
+			first = sn
+			break
+		}
+		assert.Equal(t, "web", first.Reservation())
+	})
+}
+
+// collectRecursive is the slice-building implementation Collect used before Walk, kept for comparison
+func collectRecursive(s *Subnet, filterFunc ...func(s *Subnet) bool) []*Subnet {
+	var res []*Subnet
+	if s == nil {
+		return res
+	}
+
+	match := true
+	for _, fn := range filterFunc {
+		if match = fn(s); !match {
+			break
+		}
+	}
+	if match {
+		res = append(res, s)
+	}
+
+	res = append(res, collectRecursive(s.low(), filterFunc...)...)
+	res = append(res, collectRecursive(s.high(), filterFunc...)...)
+	return res
+}
+
+func BenchmarkCollectRecursive(b *testing.B) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(b, err, "parse should return no error")
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(b, err)
+	_, err = s.AddReservation("10.0.0.0/28", "web-lb")
+	assert.NoError(b, err)
+	_, err = s.AddReservation("10.0.1.0/25", "db")
+	assert.NoError(b, err)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		collectRecursive(s)
+	}
+}
+
+func BenchmarkCollect(b *testing.B) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(b, err, "parse should return no error")
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(b, err)
+	_, err = s.AddReservation("10.0.0.0/28", "web-lb")
+	assert.NoError(b, err)
+	_, err = s.AddReservation("10.0.1.0/25", "db")
+	assert.NoError(b, err)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Collect()
+	}
+}
+
+func BenchmarkReserved(b *testing.B) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(b, err, "parse should return no error")
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(b, err)
+	_, err = s.AddReservation("10.0.0.0/28", "web-lb")
+	assert.NoError(b, err)
+	_, err = s.AddReservation("10.0.1.0/25", "db")
+	assert.NoError(b, err)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range s.Reserved() {
+		}
+	}
+}
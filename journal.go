@@ -0,0 +1,229 @@
+package subnetcalc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrInvalidJournal = errors.New("invalid journal entry")
+
+// JournalEntry records a single change to the reservations of a tree
+type JournalEntry struct {
+	Time   time.Time     `json:"time"`
+	Actor  string        `json:"actor,omitempty"`
+	Root   string        `json:"root"`
+	Op     string        `json:"op"`
+	CIDR   string        `json:"cidr"`
+	Before *JournalState `json:"before,omitempty"`
+	After  *JournalState `json:"after,omitempty"`
+}
+
+// JournalState is the reservation state of a subnet before or after a change
+type JournalState struct {
+	Name string `json:"name"`
+	Pool bool   `json:"pool,omitempty"`
+}
+
+// Journal is an append-only log of reservation changes, stored as JSON lines in a file. Register it as an
+// observer on a tree to record every change made to the reservations of the tree.
+type Journal struct {
+	mu    sync.Mutex
+	path  string
+	file  *os.File
+	actor string
+	now   func() time.Time
+	err   error
+}
+
+// OpenJournal opens the journal file at path for appending, creating it if it does not exist
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Journal{
+		path: path,
+		file: f,
+		now:  time.Now,
+	}, nil
+}
+
+// SetActor sets who is recorded as making the following changes
+func (j *Journal) SetActor(actor string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.actor = actor
+}
+
+// Notify records reservation events in the journal. Structural events are not recorded.
+func (j *Journal) Notify(e Event) {
+	entry := JournalEntry{
+		Root: e.Subnet.root().CIDR(),
+		Op:   e.Type.String(),
+		CIDR: e.Subnet.CIDR(),
+	}
+
+	switch e.Type {
+	case EventReserved:
+		entry.After = &JournalState{Name: e.Name, Pool: e.Pool}
+	case EventUnreserved:
+		entry.Before = &JournalState{Name: e.OldName, Pool: e.OldPool}
+	case EventRenamed:
+		entry.Before = &JournalState{Name: e.OldName, Pool: e.Pool}
+		entry.After = &JournalState{Name: e.Name, Pool: e.Pool}
+	case EventPoolChanged:
+		entry.Before = &JournalState{Name: e.Name, Pool: !e.Pool}
+		entry.After = &JournalState{Name: e.Name, Pool: e.Pool}
+	default:
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entry.Time = j.now().UTC()
+	entry.Actor = j.actor
+
+	data, err := json.Marshal(entry)
+	if err == nil {
+		_, err = j.file.Write(append(data, '\n'))
+	}
+	if err != nil && j.err == nil {
+		j.err = err
+	}
+}
+
+// Err returns the first error writing to the journal, as observers cannot fail the changes they observe
+func (j *Journal) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// History returns the recorded changes to subnets overlapping the CIDR, or to reservations with the name if the
+// query is not a CIDR
+func (j *Journal) History(query string) ([]JournalEntry, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := ReadJournal(f)
+	if err != nil {
+		return nil, err
+	}
+
+	match := func(e JournalEntry) bool {
+		return (e.Before != nil && e.Before.Name == query) || (e.After != nil && e.After.Name == query)
+	}
+	if c, err := toCIDR(query); err == nil {
+		q := c.subnet()
+		match = func(e JournalEntry) bool {
+			ec, err := toCIDR(e.CIDR)
+			return err == nil && q.overlaps(ec.subnet())
+		}
+	}
+
+	var res []JournalEntry
+	for _, e := range entries {
+		if match(e) {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+// ReadJournal reads all entries of a journal
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	var entries []JournalEntry
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidJournal, line, err)
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, scanner.Err()
+}
+
+// Replay rebuilds a tree by applying the journal entries in order. Policies are not checked, as the changes
+// were already accepted when recorded.
+func Replay(entries []JournalEntry) (*Subnet, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: journal is empty", ErrInvalidJournal)
+	}
+
+	root, err := Parse(entries[0].Root)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, e := range entries {
+		if err = root.replay(e); err != nil {
+			return nil, fmt.Errorf("journal entry %d: %w", i+1, err)
+		}
+	}
+
+	return root, nil
+}
+
+// replay applies a single journal entry to the tree
+func (s *Subnet) replay(e JournalEntry) error {
+	if e.Root != s.CIDR() {
+		return fmt.Errorf("%w: %s and %s", ErrRootMismatch, s.CIDR(), e.Root)
+	}
+
+	sn, err := s.findCIDR(e.CIDR)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case e.Op == EventReserved.String() && e.After != nil:
+		if sn.reserved {
+			return &ConflictError{CIDR: sn.CIDR(), Name: e.After.Name, Conflict: sn, Reservation: sn.Reservation()}
+		}
+		sn.pool = e.After.Pool
+		sn.setReservation(e.After.Name)
+
+	case e.Op == EventUnreserved.String():
+		if !sn.reserved {
+			return &SubnetError{CIDR: sn.CIDR(), Err: ErrNotReserved}
+		}
+		sn.clearReservation()
+
+	case e.Op == EventRenamed.String() && e.After != nil:
+		return sn.Rename(e.After.Name)
+
+	case e.Op == EventPoolChanged.String() && e.After != nil:
+		return sn.SetPool(e.After.Pool)
+
+	default:
+		return fmt.Errorf("%w: unknown operation %q", ErrInvalidJournal, e.Op)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package subnetcalc
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Journal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := OpenJournal(path)
+	assert.NoError(t, err)
+	defer j.Close()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	j.now = func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}
+
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	s.AddObserver(j)
+
+	j.SetActor("alice")
+	web, err := s.AddReservation("10.0.5.0/24", "web")
+	assert.NoError(t, err)
+	team, err := s.AddReservation("10.0.16.0/21", "team-x")
+	assert.NoError(t, err)
+	assert.NoError(t, team.SetPool(true))
+	_, err = team.FindFreeAndReserve(26, "team-x-a")
+	assert.NoError(t, err)
+
+	j.SetActor("bob")
+	assert.NoError(t, web.Rename("www"))
+	assert.NoError(t, web.UnReserve())
+	_, err = s.AddReservation("10.0.5.0/25", "db")
+	assert.NoError(t, err)
+	team, err = team.Grow()
+	assert.NoError(t, err)
+	assert.NoError(t, j.Err())
+
+	t.Run("Entries", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		assert.Len(t, lines, 9)
+		assert.JSONEq(t, `{"time":"2026-03-01T13:00:00Z","actor":"alice","root":"10.0.0.0/16","op":"reserved","cidr":"10.0.5.0/24","after":{"name":"web"}}`, lines[0])
+		assert.JSONEq(t, `{"time":"2026-03-01T15:00:00Z","actor":"alice","root":"10.0.0.0/16","op":"pool changed","cidr":"10.0.16.0/21","before":{"name":"team-x"},"after":{"name":"team-x","pool":true}}`, lines[2])
+	})
+
+	t.Run("History by CIDR", func(t *testing.T) {
+		history, err := j.History("10.0.5.0/24")
+		assert.NoError(t, err)
+		if assert.Len(t, history, 4) {
+			assert.Equal(t, "renamed", history[1].Op)
+			assert.Equal(t, "bob", history[1].Actor)
+			assert.Equal(t, "web", history[1].Before.Name)
+			assert.Equal(t, "www", history[1].After.Name)
+			assert.Equal(t, "10.0.5.0/25", history[3].CIDR)
+		}
+	})
+
+	t.Run("History by name", func(t *testing.T) {
+		history, err := j.History("team-x")
+		assert.NoError(t, err)
+		assert.Len(t, history, 4)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		f, err := os.Open(path)
+		assert.NoError(t, err)
+		defer f.Close()
+
+		entries, err := ReadJournal(f)
+		assert.NoError(t, err)
+
+		replayed, err := Replay(entries)
+		assert.NoError(t, err)
+
+		changes, err := Diff(s, replayed)
+		assert.NoError(t, err)
+		assert.True(t, changes.Empty(), changes.String())
+
+		pools := replayed.FindByName("team-x")
+		if assert.Len(t, pools, 1) {
+			assert.Equal(t, team.CIDR(), pools[0].CIDR())
+			assert.True(t, pools[0].IsPool())
+		}
+		assert.Equal(t, s.subReservations, replayed.subReservations)
+	})
+}
+
+func Test_JournalUnreservedPool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := OpenJournal(path)
+	assert.NoError(t, err)
+	defer j.Close()
+	j.now = func() time.Time {
+		return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	s.AddObserver(j)
+
+	sn, err := s.AddReservation("10.0.16.0/20", "team")
+	assert.NoError(t, err)
+	assert.NoError(t, sn.SetPool(true))
+	assert.NoError(t, sn.UnReserve())
+	assert.NoError(t, j.Err())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.JSONEq(t, `{"time":"2026-03-01T12:00:00Z","root":"10.0.0.0/16","op":"unreserved","cidr":"10.0.16.0/20","before":{"name":"team","pool":true}}`, lines[2])
+	}
+}
+
+func Test_ReplayErrors(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		_, err := Replay(nil)
+		assert.ErrorIs(t, err, ErrInvalidJournal)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := ReadJournal(strings.NewReader("{\"op\":\n"))
+		assert.ErrorIs(t, err, ErrInvalidJournal)
+	})
+
+	t.Run("Inconsistent", func(t *testing.T) {
+		entries, err := ReadJournal(strings.NewReader(`{"root":"10.0.0.0/16","op":"reserved","cidr":"10.0.0.0/24","after":{"name":"a"}}
+{"root":"10.0.0.0/16","op":"reserved","cidr":"10.0.0.0/24","after":{"name":"b"}}
+`))
+		assert.NoError(t, err)
+
+		_, err = Replay(entries)
+		assert.ErrorIs(t, err, ErrAlreadyReserved)
+		assert.ErrorContains(t, err, "journal entry 2")
+	})
+
+	t.Run("Different roots", func(t *testing.T) {
+		entries, err := ReadJournal(strings.NewReader(`{"root":"10.0.0.0/16","op":"reserved","cidr":"10.0.0.0/24","after":{"name":"a"}}
+{"root":"10.1.0.0/16","op":"reserved","cidr":"10.1.0.0/24","after":{"name":"b"}}
+`))
+		assert.NoError(t, err)
+
+		_, err = Replay(entries)
+		assert.ErrorIs(t, err, ErrRootMismatch)
+	})
+}
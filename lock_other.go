@@ -0,0 +1,31 @@
+//go:build !unix
+
+package subnetcalc
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+var errLockTimeout = errors.New("timed out waiting for lock file")
+
+// lockFile takes an exclusive lock by creating the file at path, waiting for it to be removed by the current
+// holder, and returns a function releasing the lock. Shared locks are not supported and are exclusive as well.
+func lockFile(path string, exclusive bool) (func(), error) {
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, errLockTimeout
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
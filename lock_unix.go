@@ -0,0 +1,31 @@
+//go:build unix
+
+package subnetcalc
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes a shared or exclusive lock on the file at path, creating it if needed, and returns a function
+// releasing the lock
+func lockFile(path string, exclusive bool) (func(), error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if err = syscall.Flock(int(f.Fd()), how); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,73 @@
+package subnetcalc
+
+import (
+	"slices"
+)
+
+// Lookup returns the most specific subnet in the tree containing the IP address. Use Owner on the result to
+// find the reservation holding the address.
+func (s *Subnet) Lookup(ip string) (*Subnet, error) {
+	addr, err := toIP(ip)
+	if err != nil {
+		return nil, err
+	}
+	if !s.contains(addr) {
+		return nil, &NotFoundError{Root: s.CIDR(), CIDR: ip}
+	}
+
+	// Reservations are indexed, so the descent can start from the closest one holding the address
+	node := s
+	if n := s.state().reserved.covering(uint32(addr), 32); n != nil && n.value.within(s) {
+		node = n.value
+	}
+	for {
+		switch {
+		case node.low() != nil && node.low().contains(addr):
+			node = node.low()
+		case node.high() != nil && node.high().contains(addr):
+			node = node.high()
+		default:
+			return node, nil
+		}
+	}
+}
+
+// Owner returns the closest reserved subnet among the subnet itself and its parents, or nil if none is reserved
+func (s *Subnet) Owner() *Subnet {
+	if n := s.state().reserved.covering(uint32(s.network()), s.Size()); n != nil {
+		return n.value
+	}
+	return nil
+}
+
+// FindByName returns all subnets in the tree reserved with the given name, in address order
+func (s *Subnet) FindByName(name string) []*Subnet {
+	res := slices.Clone(s.state().names[name])
+	slices.SortFunc(res, func(a, b *Subnet) int {
+		if a.network() != b.network() {
+			return a.network() - b.network()
+		}
+		return a.Size() - b.Size()
+	})
+	return res
+}
+
+// addName adds a subnet reserved with the name to the name index
+func (t *tree) addName(s *Subnet, name string) {
+	if t.names == nil {
+		t.names = map[string][]*Subnet{}
+	}
+	t.names[name] = append(t.names[name], s)
+}
+
+// removeName removes a subnet reserved with the name from the name index
+func (t *tree) removeName(s *Subnet, name string) {
+	subnets := slices.DeleteFunc(t.names[name], func(n *Subnet) bool {
+		return n == s
+	})
+	if len(subnets) == 0 {
+		delete(t.names, name)
+		return
+	}
+	t.names[name] = subnets
+}
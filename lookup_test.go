@@ -0,0 +1,72 @@
+package subnetcalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Lookup(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	_, err = s.AddReservation("10.0.0.0/22", "web")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.3.16/28", "web-lb")
+	assert.NoError(t, err)
+
+	t.Run("Most specific", func(t *testing.T) {
+		n, err := s.Lookup("10.0.3.17")
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.3.16/31", n.CIDR())
+		assert.Equal(t, "web-lb", n.Owner().Reservation())
+	})
+
+	t.Run("Reserved ancestor", func(t *testing.T) {
+		n, err := s.Lookup("10.0.1.1")
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.1.0/31", n.CIDR())
+		assert.Equal(t, "10.0.0.0/22", n.Owner().CIDR())
+	})
+
+	t.Run("Unreserved", func(t *testing.T) {
+		n, err := s.Lookup("10.0.200.1")
+		assert.NoError(t, err)
+		assert.Nil(t, n.Owner())
+	})
+
+	t.Run("Outside", func(t *testing.T) {
+		_, err := s.Lookup("10.1.0.1")
+		assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := s.Lookup("10.0.0")
+		assert.ErrorIs(t, err, ErrCouldNotParse)
+	})
+}
+
+func Test_FindByName(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	assert.Empty(t, s.FindByName("db-prod"))
+
+	db, err := s.AddReservation("10.0.4.0/24", "db-prod")
+	assert.NoError(t, err)
+	f, err := s.FindFreeAndReserve(24, "db-prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/24", f.CIDR())
+	web, err := s.FindFreeAndReserve(24, "web")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*Subnet{f, db}, s.FindByName("db-prod"))
+	assert.Equal(t, []*Subnet{web}, f.FindByName("web"), "index is shared by the whole tree")
+
+	assert.NoError(t, f.UnReserve())
+	assert.Equal(t, []*Subnet{db}, s.FindByName("db-prod"))
+
+	_, err = s.AddReservation("10.0.4.0/24", "other")
+	assert.ErrorIs(t, err, ErrAlreadyReserved)
+	assert.Empty(t, s.FindByName("other"))
+}
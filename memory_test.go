@@ -0,0 +1,157 @@
+package subnetcalc
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// legacySubnet is the representation of subnets before nodes were packed, kept to compare memory use against
+type legacySubnet struct {
+	cidr            net.IPNet
+	parent          *legacySubnet
+	low, high       *legacySubnet
+	reservation     string
+	subReservations int
+}
+
+// legacyParse builds a fully divided tree of the legacy representation the way Parse used to
+func legacyParse(cidr string) (*legacySubnet, error) {
+	_, n, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	s := &legacySubnet{cidr: *n}
+	s.initialize()
+	return s, nil
+}
+
+func (s *legacySubnet) initialize() {
+	size, _ := s.cidr.Mask.Size()
+	if size >= 31 {
+		return
+	}
+
+	s.low = &legacySubnet{
+		cidr:   net.IPNet{IP: s.cidr.IP, Mask: inetNToB(inetSubnetNetmask(size + 1))},
+		parent: s,
+	}
+	s.high = &legacySubnet{
+		cidr:   net.IPNet{IP: inetNToB(inetSubnetLastAddress(inetBToN(s.cidr.IP), size+1) + 1), Mask: inetNToB(inetSubnetNetmask(size + 1))},
+		parent: s,
+	}
+	s.low.initialize()
+	s.high.initialize()
+}
+
+// reserve reserves the subnet of the given size at position index within the subnet
+func (s *legacySubnet) reserve(size int, index int, name string) {
+	root, _ := s.cidr.Mask.Size()
+	for bit := size - root - 1; bit >= 0; bit-- {
+		s.subReservations++
+		if index>>bit&1 == 0 {
+			s = s.low
+		} else {
+			s = s.high
+		}
+	}
+	s.reservation = name
+}
+
+func (s *legacySubnet) count() int {
+	if s == nil {
+		return 0
+	}
+	return 1 + s.low.count() + s.high.count()
+}
+
+func Test_SubnetSize(t *testing.T) {
+	assert.Equal(t, uintptr(32), unsafe.Sizeof(Subnet{}), "a subnet should fit in 32 bytes")
+	assert.Equal(t, uintptr(96), unsafe.Sizeof(legacySubnet{}))
+}
+
+// BenchmarkParse measures building fully divided trees, reporting the memory allocated per tree node, for the
+// legacy and the packed representation
+func BenchmarkParse(b *testing.B) {
+	for _, size := range []int{20, 16, 12} {
+		cidr := fmt.Sprintf("10.0.0.0/%d", size)
+		nodes := inetSubnetAddresses(size) - 1
+
+		for _, bench := range []struct {
+			name  string
+			parse func() error
+		}{
+			{"legacy", func() error { _, err := legacyParse(cidr); return err }},
+			{"packed", func() error { _, err := Parse(cidr); return err }},
+		} {
+			b.Run(fmt.Sprintf("%d/%s", size, bench.name), func(b *testing.B) {
+				var before, after runtime.MemStats
+				runtime.ReadMemStats(&before)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					assert.NoError(b, bench.parse(), "parse should return no error")
+				}
+				b.StopTimer()
+				runtime.ReadMemStats(&after)
+
+				b.ReportMetric(float64(after.TotalAlloc-before.TotalAlloc)/float64(b.N)/float64(nodes), "B/node")
+			})
+		}
+	}
+}
+
+// BenchmarkTreeHeap reports the heap retained per node by a /12 tree with 256 reservations, for the legacy and
+// the packed representation
+func BenchmarkTreeHeap(b *testing.B) {
+	b.Run("legacy", func(b *testing.B) {
+		var s *legacySubnet
+		var before, after runtime.MemStats
+		for i := 0; i < b.N; i++ {
+			s = nil
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+
+			var err error
+			s, err = legacyParse("10.0.0.0/12")
+			assert.NoError(b, err, "parse should return no error")
+			for j := 0; j < 256; j++ {
+				s.reserve(24, j, fmt.Sprintf("net-%d", j))
+			}
+
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+		}
+
+		b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/float64(s.count()), "B/node")
+		runtime.KeepAlive(s)
+	})
+
+	b.Run("packed", func(b *testing.B) {
+		var s *Subnet
+		var before, after runtime.MemStats
+		for i := 0; i < b.N; i++ {
+			s = nil
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+
+			var err error
+			s, err = Parse("10.0.0.0/12")
+			assert.NoError(b, err, "parse should return no error")
+			for j := 0; j < 256; j++ {
+				_, err = s.FindFreeAndReserve(24, fmt.Sprintf("net-%d", j))
+				assert.NoError(b, err)
+			}
+
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+		}
+
+		b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/float64(len(s.Collect())), "B/node")
+		runtime.KeepAlive(s)
+	})
+}
@@ -0,0 +1,61 @@
+package subnetcalc
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Overlap is a pair of intersecting reservations in different trees
+type Overlap struct {
+	A, B         *Subnet // Overlapping reservations, A from the tree given first
+	TreeA, TreeB int     // Indexes of the trees of A and B in the arguments to CheckOverlaps
+}
+
+func (o Overlap) String() string {
+	return fmt.Sprintf("%s %q in %s overlaps %s %q in %s",
+		o.A.CIDR(), o.A.Reservation(), o.A.root().CIDR(), o.B.CIDR(), o.B.Reservation(), o.B.root().CIDR())
+}
+
+// CheckOverlaps returns every pair of reservations in different trees that intersect, ordered by address. The
+// reservations are swept in address order, keeping only those still open at each address, so the cost is
+// proportional to the number of reservations and overlaps rather than the number of pairs.
+func CheckOverlaps(trees ...*Subnet) []Overlap {
+	type interval struct {
+		subnet     *Subnet
+		tree       int
+		start, end int
+	}
+
+	var intervals []interval
+	for i, t := range trees {
+		for sn := range t.Reserved() {
+			intervals = append(intervals, interval{subnet: sn, tree: i, start: sn.network(), end: sn.broadcast()})
+		}
+	}
+	slices.SortStableFunc(intervals, func(a, b interval) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+		return b.end - a.end
+	})
+
+	var res []Overlap
+	var open []interval
+	for _, cur := range intervals {
+		open = slices.DeleteFunc(open, func(o interval) bool { return o.end < cur.start })
+
+		for _, o := range open {
+			if o.tree == cur.tree {
+				continue
+			}
+			if o.tree < cur.tree {
+				res = append(res, Overlap{A: o.subnet, B: cur.subnet, TreeA: o.tree, TreeB: cur.tree})
+			} else {
+				res = append(res, Overlap{A: cur.subnet, B: o.subnet, TreeA: cur.tree, TreeB: o.tree})
+			}
+		}
+		open = append(open, cur)
+	}
+
+	return res
+}
@@ -0,0 +1,73 @@
+package subnetcalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CheckOverlaps(t *testing.T) {
+	a, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = a.AddReservation("10.0.0.0/24", "vpc-a")
+	assert.NoError(t, err)
+	_, err = a.AddReservation("10.0.0.0/26", "vpc-a-web")
+	assert.NoError(t, err)
+	_, err = a.AddReservation("10.0.4.0/24", "vpc-a-db")
+	assert.NoError(t, err)
+	_, err = a.AddReservation("10.0.10.0/24", "vpc-a-spare")
+	assert.NoError(t, err)
+
+	b, err := Parse("10.0.0.0/20")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = b.AddReservation("10.0.0.128/25", "vpc-b")
+	assert.NoError(t, err)
+	_, err = b.AddReservation("10.0.4.0/22", "vpc-b-db")
+	assert.NoError(t, err)
+	_, err = b.AddReservation("10.0.11.0/24", "vpc-b-spare")
+	assert.NoError(t, err)
+
+	c, err := Parse("10.0.0.0/12")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = c.AddReservation("10.0.0.0/16", "legacy")
+	assert.NoError(t, err)
+
+	t.Run("Pairs", func(t *testing.T) {
+		res := CheckOverlaps(a, b)
+
+		var pairs [][2]string
+		for _, o := range res {
+			assert.Equal(t, 0, o.TreeA)
+			assert.Equal(t, 1, o.TreeB)
+			pairs = append(pairs, [2]string{o.A.Reservation(), o.B.Reservation()})
+		}
+		assert.Equal(t, [][2]string{{"vpc-a", "vpc-b"}, {"vpc-a-db", "vpc-b-db"}}, pairs)
+		assert.Equal(t, `10.0.0.0/24 "vpc-a" in 10.0.0.0/16 overlaps 10.0.0.128/25 "vpc-b" in 10.0.0.0/20`, res[0].String())
+	})
+
+	t.Run("Order", func(t *testing.T) {
+		res := CheckOverlaps(b, a)
+		assert.Len(t, res, 2)
+		assert.Equal(t, "vpc-b", res[0].A.Reservation(), "A is from the tree given first")
+		assert.Equal(t, 0, res[0].TreeA)
+	})
+
+	t.Run("Three", func(t *testing.T) {
+		res := CheckOverlaps(a, b, c)
+		assert.Len(t, res, 2+4+3)
+
+		var legacy int
+		for _, o := range res {
+			if o.B.Reservation() == "legacy" {
+				legacy++
+				assert.Equal(t, 2, o.TreeB)
+			}
+		}
+		assert.Equal(t, 7, legacy)
+	})
+
+	t.Run("SameTree", func(t *testing.T) {
+		assert.Empty(t, CheckOverlaps(a))
+		assert.Empty(t, CheckOverlaps())
+	})
+}
@@ -0,0 +1,199 @@
+// Package podcidr allocates pod CIDRs to Kubernetes nodes from cluster CIDRs, like the range allocator of
+// kube-controller-manager, keeping the assignments in subnetcalc stores
+package podcidr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kschjeld/subnetcalc"
+)
+
+var ErrUnsupportedFamily = errors.New("address family is not supported")
+var ErrInvalidClusterCIDR = errors.New("invalid cluster CIDR")
+
+// ReservationPrefix prefixes the node name in the reservation names of pod CIDRs. Other reservations in a
+// cluster CIDR, like a service CIDR carved out of it, are left alone.
+const ReservationPrefix = "node/"
+
+// NodeLister lists the names of the nodes in a cluster
+type NodeLister interface {
+	List(ctx context.Context) ([]string, error)
+}
+
+// FakeLister is a NodeLister with a fixed list of nodes, for testing without a cluster
+type FakeLister struct {
+	mu    sync.Mutex
+	nodes []string
+}
+
+// NewFakeLister returns a FakeLister listing the nodes
+func NewFakeLister(nodes ...string) *FakeLister {
+	return &FakeLister{nodes: nodes}
+}
+
+// Set replaces the listed nodes
+func (f *FakeLister) Set(nodes ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nodes = nodes
+}
+
+// List returns the listed nodes
+func (f *FakeLister) List(ctx context.Context) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.nodes), nil
+}
+
+// ClusterCIDR is a range pod CIDRs of a single address family are allocated from
+type ClusterCIDR struct {
+	CIDR         string           // Cluster CIDR, e.g. 10.244.0.0/16
+	NodeMaskSize int              // Size of the pod CIDR of each node, e.g. 24
+	Store        subnetcalc.Store // Store keeping the tree of the cluster CIDR
+}
+
+// Allocator assigns each node a pod CIDR from every cluster CIDR, one per address family for dual-stack
+// clusters. Only IPv4 is supported for now.
+type Allocator struct {
+	lister   NodeLister
+	clusters []ClusterCIDR
+}
+
+// New returns an Allocator for the nodes listed by lister, storing the cluster CIDRs in their stores if they
+// are empty
+func New(lister NodeLister, clusters ...ClusterCIDR) (*Allocator, error) {
+	if len(clusters) == 0 {
+		return nil, fmt.Errorf("%w: no cluster CIDR given", ErrInvalidClusterCIDR)
+	}
+
+	families := map[bool]bool{}
+	for _, c := range clusters {
+		ip, ipNet, err := net.ParseCIDR(c.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidClusterCIDR, err)
+		}
+		if ip.To4() == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedFamily, c.CIDR)
+		}
+		if families[ip.To4() != nil] {
+			return nil, fmt.Errorf("%w: more than one cluster CIDR of the address family of %s", ErrInvalidClusterCIDR, c.CIDR)
+		}
+		families[ip.To4() != nil] = true
+
+		if size, _ := ipNet.Mask.Size(); c.NodeMaskSize < size || c.NodeMaskSize > 32 {
+			return nil, fmt.Errorf("%w: node mask size %d does not fit in %s", ErrInvalidClusterCIDR, c.NodeMaskSize, c.CIDR)
+		}
+
+		if err = initStore(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Allocator{lister: lister, clusters: clusters}, nil
+}
+
+// initStore stores the tree of the cluster CIDR if the store is empty, and otherwise checks the stored tree is
+// the cluster CIDR
+func initStore(c ClusterCIDR) error {
+	root, _, err := c.Store.Load()
+	if errors.Is(err, subnetcalc.ErrEmptyStore) {
+		if root, err = subnetcalc.Parse(c.CIDR); err != nil {
+			return err
+		}
+		if _, err = c.Store.CompareAndSwap(0, root); !errors.Is(err, subnetcalc.ErrVersionConflict) {
+			return err
+		}
+		root, _, err = c.Store.Load()
+	}
+	if err != nil {
+		return err
+	}
+
+	if expected, _ := subnetcalc.Parse(c.CIDR); root.CIDR() != expected.CIDR() {
+		return fmt.Errorf("%w: %s and %s", subnetcalc.ErrRootMismatch, root.CIDR(), c.CIDR)
+	}
+	return nil
+}
+
+// Reconcile assigns pod CIDRs to listed nodes without one and releases the pod CIDRs of nodes no longer listed,
+// returning the pod CIDRs of each node in the order of the cluster CIDRs. Nodes that could not be assigned a pod
+// CIDR, because a cluster CIDR is exhausted, are reported in the error while the other changes are stored.
+func (a *Allocator) Reconcile(ctx context.Context) (map[string][]string, error) {
+	nodes, err := a.lister.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(nodes)
+	nodes = slices.Compact(nodes)
+
+	podCIDRs := map[string][]string{}
+	var errs []error
+	for _, c := range a.clusters {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var assigned map[string]string
+		var failed []error
+		err = subnetcalc.Update(c.Store, func(root *subnetcalc.Subnet) error {
+			assigned, failed = reconcile(root, c.NodeMaskSize, nodes)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for node, cidr := range assigned {
+			podCIDRs[node] = append(podCIDRs[node], cidr)
+		}
+		errs = append(errs, failed...)
+	}
+
+	return podCIDRs, errors.Join(errs...)
+}
+
+// reconcile updates the node reservations of a cluster tree, returning the pod CIDR of each node and the errors
+// assigning missing pod CIDRs
+func reconcile(root *subnetcalc.Subnet, size int, nodes []string) (map[string]string, []error) {
+	listed := map[string]bool{}
+	for _, node := range nodes {
+		listed[node] = true
+	}
+
+	assigned := map[string]string{}
+	var released []*subnetcalc.Subnet
+	for sn := range root.Reserved() {
+		node, ok := strings.CutPrefix(sn.Reservation(), ReservationPrefix)
+		switch {
+		case !ok:
+		case listed[node] && assigned[node] == "":
+			assigned[node] = sn.CIDR()
+		default:
+			released = append(released, sn)
+		}
+	}
+	for _, sn := range released {
+		_ = sn.UnReserve()
+	}
+
+	var failed []error
+	for _, node := range nodes {
+		if assigned[node] != "" {
+			continue
+		}
+		sn, err := root.FindFreeAndReserve(size, ReservationPrefix+node)
+		if err != nil {
+			failed = append(failed, fmt.Errorf("node %s: %w", node, err))
+			continue
+		}
+		assigned[node] = sn.CIDR()
+	}
+
+	return assigned, failed
+}
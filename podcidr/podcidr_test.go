@@ -0,0 +1,122 @@
+package podcidr
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/kschjeld/subnetcalc"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	store := subnetcalc.NewFileStore(filepath.Join(t.TempDir(), "cluster.json"))
+	lister := NewFakeLister("node-b", "node-a")
+
+	a, err := New(lister, ClusterCIDR{CIDR: "10.244.0.0/16", NodeMaskSize: 24, Store: store})
+	assert.NoError(t, err)
+
+	podCIDRs, err := a.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"node-a": {"10.244.0.0/24"},
+		"node-b": {"10.244.1.0/24"},
+	}, podCIDRs)
+
+	// Assignments are kept, deleted nodes released and new nodes get the free pod CIDRs
+	lister.Set("node-b", "node-c", "node-d")
+	podCIDRs, err = a.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"node-b": {"10.244.1.0/24"},
+		"node-c": {"10.244.0.0/24"},
+		"node-d": {"10.244.2.0/24"},
+	}, podCIDRs)
+
+	// Assignments are persisted in the store
+	root, _, err := store.Load()
+	assert.NoError(t, err)
+	assert.Empty(t, root.FindByName(ReservationPrefix+"node-a"))
+	assert.Equal(t, "10.244.2.0/24", root.FindByName(ReservationPrefix + "node-d")[0].CIDR())
+
+	b, err := New(lister, ClusterCIDR{CIDR: "10.244.0.0/16", NodeMaskSize: 24, Store: store})
+	assert.NoError(t, err)
+	again, err := b.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, podCIDRs, again)
+}
+
+func Test_ReconcileKeepsOtherReservations(t *testing.T) {
+	store := subnetcalc.NewFileStore(filepath.Join(t.TempDir(), "cluster.json"))
+	root, err := subnetcalc.Parse("10.244.0.0/22")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = root.AddReservation("10.244.0.0/24", "services")
+	assert.NoError(t, err)
+	_, err = store.Save(root)
+	assert.NoError(t, err)
+
+	lister := NewFakeLister("node-a", "node-b", "node-c", "node-d")
+	a, err := New(lister, ClusterCIDR{CIDR: "10.244.0.0/22", NodeMaskSize: 24, Store: store})
+	assert.NoError(t, err)
+
+	podCIDRs, err := a.Reconcile(context.Background())
+	assert.ErrorIs(t, err, subnetcalc.ErrDidNotFindSubnet, "only three pod CIDRs are free")
+	assert.ErrorContains(t, err, "node-d")
+	assert.Equal(t, map[string][]string{
+		"node-a": {"10.244.1.0/24"},
+		"node-b": {"10.244.2.0/24"},
+		"node-c": {"10.244.3.0/24"},
+	}, podCIDRs)
+
+	lister.Set()
+	podCIDRs, err = a.Reconcile(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, podCIDRs)
+
+	root, _, err = store.Load()
+	assert.NoError(t, err)
+	reserved := root.Collect(subnetcalc.SelectReserved())
+	assert.Len(t, reserved, 1)
+	assert.Equal(t, "services", reserved[0].Reservation())
+}
+
+func Test_New(t *testing.T) {
+	store := subnetcalc.NewFileStore(filepath.Join(t.TempDir(), "cluster.json"))
+	lister := NewFakeLister()
+
+	_, err := New(lister)
+	assert.ErrorIs(t, err, ErrInvalidClusterCIDR)
+
+	_, err = New(lister, ClusterCIDR{CIDR: "10.244.0.0", NodeMaskSize: 24, Store: store})
+	assert.ErrorIs(t, err, ErrInvalidClusterCIDR)
+
+	_, err = New(lister, ClusterCIDR{CIDR: "fd00:10:244::/56", NodeMaskSize: 64, Store: store})
+	assert.ErrorIs(t, err, ErrUnsupportedFamily)
+
+	_, err = New(lister, ClusterCIDR{CIDR: "10.244.0.0/16", NodeMaskSize: 8, Store: store})
+	assert.ErrorIs(t, err, ErrInvalidClusterCIDR)
+
+	_, err = New(lister,
+		ClusterCIDR{CIDR: "10.244.0.0/16", NodeMaskSize: 24, Store: store},
+		ClusterCIDR{CIDR: "10.245.0.0/16", NodeMaskSize: 24, Store: store},
+	)
+	assert.ErrorIs(t, err, ErrInvalidClusterCIDR, "only one cluster CIDR per address family")
+
+	_, err = New(lister, ClusterCIDR{CIDR: "10.244.0.0/16", NodeMaskSize: 24, Store: store})
+	assert.NoError(t, err)
+
+	_, err = New(lister, ClusterCIDR{CIDR: "10.245.0.0/16", NodeMaskSize: 24, Store: store})
+	assert.ErrorIs(t, err, subnetcalc.ErrRootMismatch)
+}
+
+func Test_ReconcileCanceled(t *testing.T) {
+	store := subnetcalc.NewFileStore(filepath.Join(t.TempDir(), "cluster.json"))
+	a, err := New(NewFakeLister("node-a"), ClusterCIDR{CIDR: "10.244.0.0/16", NodeMaskSize: 24, Store: store})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = a.Reconcile(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
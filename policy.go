@@ -0,0 +1,119 @@
+package subnetcalc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var ErrPolicyViolation = errors.New("reservation violates policy")
+
+// Policy decides if a subnet may be reserved with the given name, returning an error describing the violation if not
+type Policy interface {
+	Check(s *Subnet, name string) error
+}
+
+// PolicyFunc allows an ordinary function to be used as a Policy
+type PolicyFunc func(s *Subnet, name string) error
+
+// Check calls f(s, name)
+func (f PolicyFunc) Check(s *Subnet, name string) error {
+	return f(s, name)
+}
+
+// PolicyViolation is the error returned when a reservation is rejected by a policy, matching ErrPolicyViolation
+type PolicyViolation struct {
+	CIDR string
+	Name string
+	Err  error
+}
+
+func (v *PolicyViolation) Error() string {
+	return fmt.Sprintf("%v: %s (%s): %v", ErrPolicyViolation, v.CIDR, v.Name, v.Err)
+}
+
+func (v *PolicyViolation) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
+
+func (v *PolicyViolation) Unwrap() error {
+	return v.Err
+}
+
+// MaxSize is a policy rejecting reservations smaller than a subnet of the given size, e.g. MaxSize(28) rejects a /29
+func MaxSize(size int) Policy {
+	return PolicyFunc(func(s *Subnet, name string) error {
+		if s.Size() > size {
+			return fmt.Errorf("subnets smaller than /%d are not allowed", size)
+		}
+		return nil
+	})
+}
+
+// MinSize is a policy rejecting reservations larger than a subnet of the given size, e.g. MinSize(20) rejects a /19
+func MinSize(size int) Policy {
+	return PolicyFunc(func(s *Subnet, name string) error {
+		if s.Size() < size {
+			return fmt.Errorf("subnets larger than /%d are not allowed", size)
+		}
+		return nil
+	})
+}
+
+// NamePattern is a policy rejecting reservation names not matching the regular expression
+func NamePattern(re *regexp.Regexp) Policy {
+	return PolicyFunc(func(s *Subnet, name string) error {
+		if !re.MatchString(name) {
+			return fmt.Errorf("name does not match %s", re)
+		}
+		return nil
+	})
+}
+
+// NotWithin is a policy rejecting reservations overlapping the given CIDR range
+func NotWithin(cidr string) (Policy, error) {
+	c, err := toCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	forbidden := c.subnet()
+
+	return PolicyFunc(func(s *Subnet, name string) error {
+		if s.overlaps(forbidden) {
+			return fmt.Errorf("subnets within %s are not allowed", forbidden.CIDR())
+		}
+		return nil
+	}), nil
+}
+
+// AddPolicy registers policies on the tree, to be checked by every following reservation
+func (s *Subnet) AddPolicy(policies ...Policy) {
+	st := s.state()
+	st.policies = append(st.policies, policies...)
+}
+
+// Validate audits the existing reservations of the tree against its registered policies and any additional
+// policies given, and returns all violations
+func Validate(root *Subnet, policies ...Policy) []*PolicyViolation {
+	policies = append(append([]Policy{}, root.state().policies...), policies...)
+
+	var violations []*PolicyViolation
+	for _, sn := range root.sortedReservations() {
+		for _, p := range policies {
+			if err := p.Check(sn, sn.Reservation()); err != nil {
+				violations = append(violations, &PolicyViolation{CIDR: sn.CIDR(), Name: sn.Reservation(), Err: err})
+			}
+		}
+	}
+	return violations
+}
+
+// checkPolicies checks a reservation of the subnet against the policies of the tree
+func (s *Subnet) checkPolicies(name string) error {
+	for _, p := range s.state().policies {
+		if err := p.Check(s, name); err != nil {
+			return &PolicyViolation{CIDR: s.CIDR(), Name: name, Err: err}
+		}
+	}
+	return nil
+}
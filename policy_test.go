@@ -0,0 +1,132 @@
+package subnetcalc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Policy(t *testing.T) {
+	t.Run("Reserve", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		s.AddPolicy(MaxSize(28))
+
+		f, err := s.FindFree(29)
+		assert.NoError(t, err)
+
+		err = f.Reserve("too-small")
+		assert.ErrorIs(t, err, ErrPolicyViolation)
+		assert.Equal(t, "", f.Reservation())
+		assert.False(t, s.HasChildReservations())
+
+		var violation *PolicyViolation
+		if assert.True(t, errors.As(err, &violation)) {
+			assert.Equal(t, "10.0.0.0/29", violation.CIDR)
+			assert.Equal(t, "too-small", violation.Name)
+		}
+	})
+
+	t.Run("AddReservation", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		s.AddPolicy(NamePattern(regexp.MustCompile(`^[a-z]+-(prod|dev)$`)))
+
+		_, err = s.AddReservation("10.0.0.0/24", "Web Prod")
+		assert.ErrorIs(t, err, ErrPolicyViolation)
+
+		_, err = s.AddReservation("10.0.0.0/24", "web-prod")
+		assert.NoError(t, err)
+	})
+
+	t.Run("FindFreeAndReserve", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		top, err := NotWithin("10.0.240.0/20")
+		assert.NoError(t, err)
+		s.AddPolicy(top, MinSize(20))
+
+		_, err = s.FindFreeAndReserve(17, "too-large")
+		assert.ErrorIs(t, err, ErrPolicyViolation)
+
+		_, err = s.AddReservation("10.0.0.0/17", "low")
+		assert.ErrorIs(t, err, ErrPolicyViolation)
+
+		_, err = s.AddReservation("10.0.240.0/24", "top")
+		assert.ErrorIs(t, err, ErrPolicyViolation)
+
+		sn, err := s.FindFreeAndReserve(24, "ok")
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.0.0/24", sn.CIDR())
+	})
+
+	t.Run("FindFreeAndReserve skips forbidden", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		low, err := NotWithin("10.0.0.0/20")
+		assert.NoError(t, err)
+		s.AddPolicy(low)
+
+		sn, err := s.FindFreeAndReserve(24, "x")
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.16.0/24", sn.CIDR())
+	})
+
+	t.Run("FindFreeDeterministicAndReserve skips forbidden", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/24")
+		assert.NoError(t, err, "parse should return no error")
+
+		high, err := NotWithin("10.0.0.128/25")
+		assert.NoError(t, err)
+		s.AddPolicy(high)
+
+		for i := 0; i < 4; i++ {
+			sn, err := s.FindFreeDeterministicAndReserve(27, fmt.Sprintf("net-%d", i))
+			assert.NoError(t, err)
+			assert.True(t, sn.within(s.low()), "%s should be in the allowed half", sn.CIDR())
+		}
+
+		_, err = s.FindFreeDeterministicAndReserve(27, "net-4")
+		assert.ErrorIs(t, err, ErrPolicyViolation)
+	})
+
+	t.Run("Invalid NotWithin", func(t *testing.T) {
+		_, err := NotWithin("10.0.0.0/40")
+		assert.ErrorIs(t, err, ErrCouldNotParse)
+	})
+}
+
+func Test_Validate(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	_, err = s.AddReservation("10.0.0.0/24", "web-prod")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.1.0/30", "link")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.255.0/24", "Mgmt")
+	assert.NoError(t, err)
+
+	top, err := NotWithin("10.0.240.0/20")
+	assert.NoError(t, err)
+	s.AddPolicy(MaxSize(28))
+
+	violations := Validate(s, top, NamePattern(regexp.MustCompile(`^[a-z-]+$`)))
+	if assert.Len(t, violations, 3) {
+		assert.Equal(t, "10.0.1.0/30", violations[0].CIDR)
+		assert.Equal(t, "10.0.255.0/24", violations[1].CIDR)
+		assert.Equal(t, "10.0.255.0/24", violations[2].CIDR)
+		assert.ErrorIs(t, violations[0], ErrPolicyViolation)
+	}
+
+	// Only the registered policy applies without additional ones
+	violations = Validate(s)
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, "10.0.1.0/30", violations[0].CIDR)
+	}
+}
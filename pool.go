@@ -0,0 +1,65 @@
+package subnetcalc
+
+import (
+	"errors"
+)
+
+var ErrPoolInUse = errors.New("pool has active allocations")
+
+// Usage summarizes the reservations within a subnet
+type Usage struct {
+	Pools              int // Number of reservations delegated as pools
+	Allocations        int // Number of leaf reservations, including the ones within pools
+	DelegatedAddresses int // Addresses delegated to pools
+	AllocatedAddresses int // Addresses held by leaf reservations
+	FreeAddresses      int // Addresses available for allocation from the subnet
+}
+
+// SetPool marks a reserved subnet as a pool, delegating allocation of subnets within it to the reservation owner.
+// FindFree and FindFreeAndReserve called on a pool allocate from it, while searches from outside the pool
+// treat it as any other reservation.
+func (s *Subnet) SetPool(pool bool) error {
+	if !s.reserved {
+		return &SubnetError{CIDR: s.CIDR(), Err: ErrNotReserved}
+	}
+
+	if s.pool != pool {
+		s.pool = pool
+		s.notify(Event{Type: EventPoolChanged, Name: s.Reservation(), Pool: pool})
+	}
+	return nil
+}
+
+// IsPool is true if the subnet is a reserved pool
+func (s *Subnet) IsPool() bool {
+	return s.pool
+}
+
+// Usage returns accounting of the pools and leaf reservations within the subnet, not including the subnet itself
+func (s *Subnet) Usage() Usage {
+	var u Usage
+
+	for _, c := range []*Subnet{s.low(), s.high()} {
+		for r := range c.Reserved() {
+			if r.pool {
+				u.Pools++
+				u.DelegatedAddresses += inetSubnetAddresses(r.Size())
+			} else {
+				u.Allocations++
+				u.AllocatedAddresses += inetSubnetAddresses(r.Size())
+			}
+		}
+	}
+
+	if s.subReservations == 0 {
+		u.FreeAddresses = inetSubnetAddresses(s.Size())
+		return u
+	}
+	for _, c := range []*Subnet{s.low(), s.high()} {
+		for f := range c.Available() {
+			u.FreeAddresses += inetSubnetAddresses(f.Size())
+		}
+	}
+
+	return u
+}
@@ -0,0 +1,173 @@
+package subnetcalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Pool(t *testing.T) {
+	t.Run("Allocate within pool", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		team, err := s.FindFreeAndReserve(20, "team-x")
+		assert.NoError(t, err)
+		assert.NoError(t, team.SetPool(true))
+		assert.True(t, team.IsPool())
+
+		a, err := team.FindFreeAndReserve(26, "team-x-a")
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.0.0/26", a.CIDR())
+		b, err := team.FindFreeAndReserve(26, "team-x-b")
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.0.64/26", b.CIDR())
+
+		other, err := s.FindFreeAndReserve(26, "other")
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.16.0/26", other.CIDR(), "pool is not searched from outside")
+
+		assert.Equal(t, 2, team.subReservations)
+		assert.Equal(t, 4, s.subReservations)
+	})
+
+	t.Run("Pool exhausted", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		team, err := s.AddReservation("10.0.0.0/25", "team-x")
+		assert.NoError(t, err)
+		assert.NoError(t, team.SetPool(true))
+
+		_, err = team.FindFreeAndReserve(26, "a")
+		assert.NoError(t, err)
+		_, err = team.FindFreeAndReserve(26, "b")
+		assert.NoError(t, err)
+
+		_, err = team.FindFreeAndReserve(26, "c")
+		assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+		assert.EqualError(t, err, "could not find suitable subnet: no free /26 in 10.0.0.0/25")
+	})
+
+	t.Run("Not a pool", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		team, err := s.AddReservation("10.0.0.0/20", "team-x")
+		assert.NoError(t, err)
+
+		_, err = team.FindFreeAndReserve(26, "a")
+		assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+
+		assert.ErrorIs(t, s.SetPool(true), ErrNotReserved)
+	})
+
+	t.Run("Unreserve pool", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		team, err := s.AddReservation("10.0.0.0/20", "team-x")
+		assert.NoError(t, err)
+		assert.NoError(t, team.SetPool(true))
+
+		a, err := team.FindFreeAndReserve(26, "a")
+		assert.NoError(t, err)
+
+		assert.ErrorIs(t, team.UnReserve(), ErrPoolInUse)
+		assert.NoError(t, a.UnReserve())
+		assert.NoError(t, team.UnReserve())
+		assert.False(t, team.IsPool())
+	})
+
+	t.Run("Move within and grow pool", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		team, err := s.AddReservation("10.0.0.0/21", "team-x")
+		assert.NoError(t, err)
+		assert.NoError(t, team.SetPool(true))
+
+		a, err := team.FindFreeAndReserve(26, "a")
+		assert.NoError(t, err)
+
+		a, err = a.Move("10.0.1.0/26")
+		assert.NoError(t, err, "allocation can move within its pool")
+		assert.Equal(t, "a", a.Reservation())
+
+		_, err = team.Move("10.0.128.0/21")
+		assert.ErrorIs(t, err, ErrPoolInUse)
+
+		grown, err := team.Grow()
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.0.0/20", grown.CIDR())
+		assert.True(t, grown.IsPool())
+		assert.False(t, team.IsPool())
+		assert.Equal(t, 1, grown.subReservations)
+	})
+
+	t.Run("Grow into pool", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		team, err := s.AddReservation("10.0.0.0/23", "team-x")
+		assert.NoError(t, err)
+		assert.NoError(t, team.SetPool(true))
+		app, err := team.FindFreeAndReserve(24, "app")
+		assert.NoError(t, err)
+
+		_, err = app.Grow()
+		assert.ErrorIs(t, err, ErrAlreadyReserved)
+		assert.Equal(t, "team-x", team.Reservation())
+		assert.True(t, team.IsPool())
+		assert.Equal(t, "app", app.Reservation())
+		assert.Equal(t, []*Subnet{team}, s.FindByName("team-x"))
+
+		assert.NoError(t, app.UnReserve())
+		assert.NoError(t, team.UnReserve())
+		assert.False(t, s.HasChildReservations())
+	})
+
+	t.Run("Move onto pool", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		team, err := s.AddReservation("10.0.0.0/21", "team-x")
+		assert.NoError(t, err)
+		assert.NoError(t, team.SetPool(true))
+		a, err := team.FindFreeAndReserve(26, "a")
+		assert.NoError(t, err)
+
+		_, err = a.Move("10.0.0.0/21")
+		assert.ErrorIs(t, err, ErrAlreadyReserved)
+		assert.Equal(t, "team-x", team.Reservation())
+		assert.Equal(t, "a", a.Reservation())
+		assert.Equal(t, 2, s.subReservations)
+	})
+}
+
+func Test_Usage(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	team, err := s.AddReservation("10.0.0.0/20", "team-x")
+	assert.NoError(t, err)
+	assert.NoError(t, team.SetPool(true))
+	_, err = team.FindFreeAndReserve(24, "team-x-a")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.16.0/24", "leaf")
+	assert.NoError(t, err)
+
+	assert.Equal(t, Usage{
+		Pools:              1,
+		Allocations:        2,
+		DelegatedAddresses: 4096,
+		AllocatedAddresses: 512,
+		FreeAddresses:      65536 - 4096 - 256,
+	}, s.Usage())
+
+	assert.Equal(t, Usage{
+		Allocations:        1,
+		AllocatedAddresses: 256,
+		FreeAddresses:      4096 - 256,
+	}, team.Usage())
+}
@@ -0,0 +1,245 @@
+package subnetcalc
+
+import (
+	"iter"
+	"math/bits"
+	"strconv"
+)
+
+// PrefixSet maps IPv4 prefixes to values, kept in a path-compressed binary trie. Every operation visits at most
+// one node per prefix bit, regardless of the number of prefixes in the set. The zero value is an empty set.
+type PrefixSet[T any] struct {
+	root *prefixNode[T]
+	len  int
+}
+
+type prefixNode[T any] struct {
+	addr     uint32
+	bits     int
+	set      bool
+	value    T
+	children [2]*prefixNode[T]
+}
+
+// Insert adds the prefix with the value, replacing the value if the prefix is already in the set
+func (p *PrefixSet[T]) Insert(cidr string, value T) error {
+	addr, size, err := parsePrefix(cidr)
+	if err != nil {
+		return err
+	}
+	p.insert(addr, size, value)
+	return nil
+}
+
+// Delete removes the prefix, returning false if it was not in the set
+func (p *PrefixSet[T]) Delete(cidr string) (bool, error) {
+	addr, size, err := parsePrefix(cidr)
+	if err != nil {
+		return false, err
+	}
+	return p.delete(addr, size), nil
+}
+
+// Get returns the value of the prefix, if it is in the set
+func (p *PrefixSet[T]) Get(cidr string) (T, bool, error) {
+	var zero T
+	addr, size, err := parsePrefix(cidr)
+	if err != nil {
+		return zero, false, err
+	}
+	for n := p.root; n != nil && n.bits <= size && prefixContains(n.addr, n.bits, addr); n = n.children[addrBit(addr, n.bits)] {
+		if n.bits == size {
+			if n.set {
+				return n.value, true, nil
+			}
+			break
+		}
+	}
+	return zero, false, nil
+}
+
+// LongestMatch returns the most specific prefix in the set containing the IP address and its value
+func (p *PrefixSet[T]) LongestMatch(ip string) (string, T, bool, error) {
+	var zero T
+	addr, err := toIP(ip)
+	if err != nil {
+		return "", zero, false, err
+	}
+
+	n := p.covering(uint32(addr), 32)
+	if n == nil {
+		return "", zero, false, nil
+	}
+	return prefixString(n.addr, n.bits), n.value, true, nil
+}
+
+// Overlaps is true if any prefix in the set intersects the CIDR range
+func (p *PrefixSet[T]) Overlaps(cidr string) (bool, error) {
+	addr, size, err := parsePrefix(cidr)
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	p.overlapping(addr, size, func(*prefixNode[T]) bool {
+		found = true
+		return false
+	})
+	return found, nil
+}
+
+// All iterates over the prefixes in the set and their values in address order, shorter prefixes first
+func (p *PrefixSet[T]) All() iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		p.walk(p.root, func(n *prefixNode[T]) bool {
+			return yield(prefixString(n.addr, n.bits), n.value)
+		})
+	}
+}
+
+// Len returns the number of prefixes in the set
+func (p *PrefixSet[T]) Len() int {
+	return p.len
+}
+
+func (p *PrefixSet[T]) insert(addr uint32, size int, value T) {
+	link := &p.root
+	for {
+		n := *link
+		if n == nil {
+			*link = &prefixNode[T]{addr: addr, bits: size, set: true, value: value}
+			p.len++
+			return
+		}
+
+		common := commonPrefixLen(n.addr, n.bits, addr, size)
+		switch {
+		case common == n.bits && common == size:
+			if !n.set {
+				p.len++
+			}
+			n.set, n.value = true, value
+			return
+
+		case common == n.bits:
+			link = &n.children[addrBit(addr, n.bits)]
+			continue
+
+		case common == size:
+			leaf := &prefixNode[T]{addr: addr, bits: size, set: true, value: value}
+			leaf.children[addrBit(n.addr, size)] = n
+			*link = leaf
+
+		default:
+			branch := &prefixNode[T]{addr: addr &^ (^uint32(0) >> common), bits: common}
+			branch.children[addrBit(n.addr, common)] = n
+			branch.children[addrBit(addr, common)] = &prefixNode[T]{addr: addr, bits: size, set: true, value: value}
+			*link = branch
+		}
+		p.len++
+		return
+	}
+}
+
+func (p *PrefixSet[T]) delete(addr uint32, size int) bool {
+	// Links to the nodes on the path, to remove nodes no longer needed bottom up
+	links := []**prefixNode[T]{&p.root}
+	for n := p.root; n != nil && n.bits <= size && prefixContains(n.addr, n.bits, addr); {
+		if n.bits == size {
+			if !n.set {
+				return false
+			}
+			var zero T
+			n.set, n.value = false, zero
+			p.len--
+
+			for i := len(links) - 1; i >= 0; i-- {
+				node := *links[i]
+				switch {
+				case node.set:
+					return true
+				case node.children[0] == nil:
+					*links[i] = node.children[1]
+				case node.children[1] == nil:
+					*links[i] = node.children[0]
+				default:
+					return true
+				}
+			}
+			return true
+		}
+
+		link := &n.children[addrBit(addr, n.bits)]
+		links = append(links, link)
+		n = *link
+	}
+	return false
+}
+
+// covering returns the longest prefix in the set containing the prefix, or nil if there is none
+func (p *PrefixSet[T]) covering(addr uint32, size int) *prefixNode[T] {
+	var found *prefixNode[T]
+	for n := p.root; n != nil && n.bits <= size && prefixContains(n.addr, n.bits, addr); {
+		if n.set {
+			found = n
+		}
+		if n.bits == 32 {
+			break
+		}
+		n = n.children[addrBit(addr, n.bits)]
+	}
+	return found
+}
+
+// overlapping calls fn for the prefixes in the set intersecting the prefix, first those containing it from the
+// shortest, then those within it in address order, until fn returns false
+func (p *PrefixSet[T]) overlapping(addr uint32, size int, fn func(n *prefixNode[T]) bool) {
+	n := p.root
+	for n != nil && n.bits < size && prefixContains(n.addr, n.bits, addr) {
+		if n.set && !fn(n) {
+			return
+		}
+		n = n.children[addrBit(addr, n.bits)]
+	}
+	if n != nil && prefixContains(addr, size, n.addr) {
+		p.walk(n, fn)
+	}
+}
+
+// walk calls fn for the prefixes in the subtrie in address order, returning false if fn stopped the walk
+func (p *PrefixSet[T]) walk(n *prefixNode[T], fn func(n *prefixNode[T]) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.set && !fn(n) {
+		return false
+	}
+	return p.walk(n.children[0], fn) && p.walk(n.children[1], fn)
+}
+
+func parsePrefix(cidr string) (uint32, int, error) {
+	c, err := toCIDR(cidr)
+	if err != nil {
+		return 0, 0, err
+	}
+	return c.addr, int(c.bits), nil
+}
+
+func prefixString(addr uint32, size int) string {
+	return inetNToA(int(addr)) + "/" + strconv.Itoa(size)
+}
+
+// prefixContains is true if the prefix contains the address
+func prefixContains(prefix uint32, size int, addr uint32) bool {
+	return size == 0 || (prefix^addr)>>(32-size) == 0
+}
+
+// commonPrefixLen returns the length of the longest prefix shared by the two prefixes
+func commonPrefixLen(a uint32, aSize int, b uint32, bSize int) int {
+	return min(aSize, bSize, bits.LeadingZeros32(a^b))
+}
+
+// addrBit returns the bit of the address following a prefix of the given size
+func addrBit(addr uint32, size int) int {
+	return int(addr >> (31 - size) & 1)
+}
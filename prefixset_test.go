@@ -0,0 +1,260 @@
+package subnetcalc
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PrefixSet(t *testing.T) {
+	t.Run("All", func(t *testing.T) {
+		p := &PrefixSet[string]{}
+		for _, cidr := range []string{"10.0.0.0/8", "10.0.0.0/16", "10.0.1.0/24", "10.0.0.128/25", "192.168.0.0/16", "10.0.1.7/32"} {
+			assert.NoError(t, p.Insert(cidr, "net "+cidr))
+		}
+		assert.Equal(t, 6, p.Len())
+
+		var keys []string
+		for cidr, v := range p.All() {
+			assert.Equal(t, "net "+cidr, v)
+			keys = append(keys, cidr)
+		}
+		assert.Equal(t, []string{"10.0.0.0/8", "10.0.0.0/16", "10.0.0.128/25", "10.0.1.0/24", "10.0.1.7/32", "192.168.0.0/16"}, keys)
+	})
+
+	t.Run("InsertReplaces", func(t *testing.T) {
+		p := &PrefixSet[string]{}
+		for _, cidr := range []string{"10.0.0.0/8", "10.0.0.0/16", "10.0.1.0/24", "10.0.0.128/25", "192.168.0.0/16", "10.0.1.7/32"} {
+			assert.NoError(t, p.Insert(cidr, "net "+cidr))
+		}
+		assert.NoError(t, p.Insert("10.0.1.1/24", "replaced"))
+		assert.Equal(t, 6, p.Len())
+
+		v, ok, err := p.Get("10.0.1.0/24")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "replaced", v)
+
+		_, ok, err = p.Get("10.0.0.0/24")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("LongestMatch", func(t *testing.T) {
+		p := &PrefixSet[string]{}
+		for _, cidr := range []string{"10.0.0.0/8", "10.0.0.0/16", "10.0.1.0/24", "10.0.0.128/25", "192.168.0.0/16", "10.0.1.7/32"} {
+			assert.NoError(t, p.Insert(cidr, "net "+cidr))
+		}
+		for ip, expected := range map[string]string{
+			"10.0.1.7":      "10.0.1.7/32",
+			"10.0.1.8":      "10.0.1.0/24",
+			"10.0.0.200":    "10.0.0.128/25",
+			"10.0.0.1":      "10.0.0.0/16",
+			"10.200.0.1":    "10.0.0.0/8",
+			"192.168.255.1": "192.168.0.0/16",
+		} {
+			cidr, v, ok, err := p.LongestMatch(ip)
+			assert.NoError(t, err)
+			assert.True(t, ok, ip)
+			assert.Equal(t, expected, cidr, ip)
+			assert.Equal(t, "net "+expected, v)
+		}
+
+		_, _, ok, err := p.LongestMatch("172.16.0.1")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		_, _, _, err = p.LongestMatch("not an ip")
+		assert.ErrorIs(t, err, ErrCouldNotParse)
+	})
+
+	t.Run("Overlaps", func(t *testing.T) {
+		p := &PrefixSet[string]{}
+		for _, cidr := range []string{"10.0.0.0/8", "10.0.0.0/16", "10.0.1.0/24", "10.0.0.128/25", "192.168.0.0/16", "10.0.1.7/32"} {
+			assert.NoError(t, p.Insert(cidr, "net "+cidr))
+		}
+		for cidr, expected := range map[string]bool{
+			"10.1.0.0/16":    true,
+			"0.0.0.0/0":      true,
+			"192.0.0.0/8":    true,
+			"192.168.3.0/24": true,
+			"172.16.0.0/12":  false,
+			"11.0.0.0/8":     false,
+		} {
+			ok, err := p.Overlaps(cidr)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, ok, cidr)
+		}
+
+		_, err := p.Overlaps("2001:db8::/32")
+		assert.ErrorIs(t, err, ErrCouldNotParse)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		p := &PrefixSet[string]{}
+		for _, cidr := range []string{"10.0.0.0/8", "10.0.0.0/16", "10.0.1.0/24", "10.0.0.128/25", "192.168.0.0/16", "10.0.1.7/32"} {
+			assert.NoError(t, p.Insert(cidr, "net "+cidr))
+		}
+
+		ok, err := p.Delete("10.0.0.0/16")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = p.Delete("10.0.0.0/16")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		ok, err = p.Delete("10.0.2.0/24")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		cidr, _, _, _ := p.LongestMatch("10.0.0.1")
+		assert.Equal(t, "10.0.0.0/8", cidr)
+
+		for _, c := range []string{"10.0.0.0/8", "10.0.1.0/24", "10.0.0.128/25", "192.168.0.0/16", "10.0.1.7/32"} {
+			ok, err = p.Delete(c)
+			assert.NoError(t, err)
+			assert.True(t, ok, c)
+		}
+		assert.Equal(t, 0, p.Len())
+		assert.Nil(t, p.root, "deleting all prefixes leaves no nodes")
+	})
+}
+
+func Test_PrefixSetRandom(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	p := &PrefixSet[int]{}
+	ref := map[string]int{}
+
+	randomPrefix := func() string {
+		size := 8 + rnd.Intn(25)
+		addr := (10<<24 | rnd.Intn(1<<16)<<8 | rnd.Intn(256)) &^ (inetSubnetAddresses(size) - 1)
+		return fmt.Sprintf("%s/%d", inetNToA(addr), size)
+	}
+
+	for i := 0; i < 2000; i++ {
+		cidr := randomPrefix()
+		if rnd.Intn(3) == 0 {
+			ok, err := p.Delete(cidr)
+			assert.NoError(t, err)
+			_, expected := ref[cidr]
+			assert.Equal(t, expected, ok)
+			delete(ref, cidr)
+		} else {
+			assert.NoError(t, p.Insert(cidr, i))
+			ref[cidr] = i
+		}
+		assert.Equal(t, len(ref), p.Len())
+
+		query := randomPrefix()
+		qc, _ := toCIDR(query)
+		q := qc.subnet()
+		expected := false
+		for c := range ref {
+			o, _ := toCIDR(c)
+			if o.subnet().overlaps(q) {
+				expected = true
+			}
+		}
+		ok, err := p.Overlaps(query)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ok, query)
+	}
+
+	var keys []string
+	for cidr, v := range p.All() {
+		assert.Equal(t, ref[cidr], v)
+		keys = append(keys, cidr)
+	}
+	assert.Len(t, keys, len(ref))
+	assert.True(t, slices.IsSortedFunc(keys, func(a, b string) int {
+		pa, _ := toCIDR(a)
+		pb, _ := toCIDR(b)
+		ca, cb := pa.subnet(), pb.subnet()
+		if ca.network() != cb.network() {
+			return ca.network() - cb.network()
+		}
+		return ca.Size() - cb.Size()
+	}))
+}
+
+func Test_ReservedIndex(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.0.0/28", "web-lb")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.1.0/25", "db")
+	assert.NoError(t, err)
+
+	index := func() []string {
+		var res []string
+		for cidr, sn := range s.state().reserved.All() {
+			assert.Equal(t, cidr, sn.CIDR())
+			res = append(res, cidr)
+		}
+		return res
+	}
+	assert.Equal(t, []string{"10.0.0.0/24", "10.0.0.0/28", "10.0.1.0/25"}, index())
+
+	db := s.FindByName("db")[0]
+	moved, err := db.Move("10.0.2.0/25")
+	assert.NoError(t, err)
+	_, err = moved.Grow()
+	assert.NoError(t, err)
+	lb := s.FindByName("web-lb")[0]
+	assert.NoError(t, lb.UnReserve())
+	assert.Equal(t, []string{"10.0.0.0/24", "10.0.2.0/24"}, index())
+
+	sn, err := s.Lookup("10.0.2.200")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.2.200/31", sn.CIDR())
+	assert.Equal(t, "db", sn.Owner().Reservation())
+
+	web := s.FindByName("web")[0]
+	sn, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(t, err, "readding with the same name does not fail")
+	assert.Same(t, web, sn)
+	_, err = s.AddReservation("10.0.0.0/24", "other")
+	assert.ErrorIs(t, err, ErrAlreadyReserved)
+	_, err = s.high().AddReservation("10.0.0.0/24", "web")
+	assert.ErrorIs(t, err, ErrDidNotFindSubnet, "reservations outside the subnet are not found")
+}
+
+func BenchmarkMoveConflict(b *testing.B) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(b, err, "parse should return no error")
+	for i := 0; i < 256; i++ {
+		_, err = s.AddReservation(fmt.Sprintf("10.0.%d.0/25", i), fmt.Sprintf("net-%d", i))
+		assert.NoError(b, err)
+	}
+	last := s.FindByName("net-255")[0]
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err = last.Move("10.0.0.0/17"); err == nil {
+			b.Fatal("expected conflict")
+		}
+	}
+}
+
+func BenchmarkOwner(b *testing.B) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(b, err, "parse should return no error")
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(b, err)
+
+	sn, err := s.Lookup("10.0.0.1")
+	assert.NoError(b, err)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if sn.Owner() == nil {
+			b.Fatal("expected owner")
+		}
+	}
+}
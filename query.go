@@ -0,0 +1,323 @@
+package subnetcalc
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ParseQuery parses a textual query into a collector selector, e.g. "reserved and size>=24 and within 10.0.8.0/21".
+//
+// Queries combine the following predicates with "and", "or", "not" and parentheses, with "and" binding tighter
+// than "or":
+//
+//	reserved             subnets with a reservation
+//	available            free subnets without reserved children
+//	size <op> N          subnets by size, where <op> is one of = != < <= > >=
+//	depth <op> N         subnets by depth below the root
+//	within CIDR          subnets contained in the CIDR range
+//	contains IP          subnets containing the IP address
+//	name = "value"       reserved subnets with the given name
+//	name ~ "regexp"      reserved subnets with a name matching the regular expression
+func ParseQuery(query string) (func(s *Subnet) bool, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+	return filter, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenOpen
+	tokenClose
+)
+
+type queryToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenizeQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	isOperator := func(r byte) bool { return strings.IndexByte("<>=!~", r) >= 0 }
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokenOpen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokenClose, text: ")", pos: i})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(query) && query[end] != '"' {
+				if query[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(query) {
+				return nil, fmt.Errorf("%w: unterminated string at position %d", ErrInvalidQuery, i)
+			}
+			text, err := strconv.Unquote(query[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid string at position %d", ErrInvalidQuery, i)
+			}
+			tokens = append(tokens, queryToken{kind: tokenString, text: text, pos: i})
+			i = end + 1
+		case isOperator(c):
+			end := i
+			for end < len(query) && isOperator(query[end]) {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: tokenOperator, text: query[i:end], pos: i})
+			i = end
+		default:
+			end := i
+			for end < len(query) && !unicode.IsSpace(rune(query[end])) && !isOperator(query[end]) && strings.IndexByte("()\"", query[end]) < 0 {
+				end++
+			}
+			tokens = append(tokens, queryToken{kind: tokenWord, text: query[i:end], pos: i})
+			i = end
+		}
+	}
+
+	return append(tokens, queryToken{kind: tokenEOF, text: "end of query", pos: len(query)}), nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *queryParser) keyword(tok queryToken, word string) bool {
+	return tok.kind == tokenWord && strings.EqualFold(tok.text, word)
+}
+
+func (p *queryParser) errorf(tok queryToken, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidQuery, fmt.Sprintf(format, args...), tok.pos)
+}
+
+func (p *queryParser) parseOr() (func(s *Subnet) bool, error) {
+	filter, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []func(s *Subnet) bool{filter}
+	for p.keyword(p.peek(), "or") {
+		p.next()
+		if filter, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+	return Or(filters...), nil
+}
+
+func (p *queryParser) parseAnd() (func(s *Subnet) bool, error) {
+	filter, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	filters := []func(s *Subnet) bool{filter}
+	for p.keyword(p.peek(), "and") {
+		p.next()
+		if filter, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	if len(filters) == 1 {
+		return filters[0], nil
+	}
+	return And(filters...), nil
+}
+
+func (p *queryParser) parseUnary() (func(s *Subnet) bool, error) {
+	tok := p.next()
+
+	switch {
+	case p.keyword(tok, "not"):
+		filter, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(filter), nil
+
+	case tok.kind == tokenOpen:
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenClose {
+			return nil, p.errorf(closing, "expected \")\", got %q", closing.text)
+		}
+		return filter, nil
+
+	case p.keyword(tok, "reserved"):
+		return SelectReserved(), nil
+
+	case p.keyword(tok, "available"):
+		return SelectAvailable(), nil
+
+	case p.keyword(tok, "size"):
+		op, n, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		return sizeComparison(op, n), nil
+
+	case p.keyword(tok, "depth"):
+		op, n, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		return func(s *Subnet) bool {
+			return compare(op, s.depth(), n)
+		}, nil
+
+	case p.keyword(tok, "within"):
+		arg := p.next()
+		if arg.kind != tokenWord {
+			return nil, p.errorf(arg, "expected CIDR, got %q", arg.text)
+		}
+		fn, err := SelectWithin(arg.text)
+		if err != nil {
+			return nil, p.errorf(arg, "expected CIDR, got %q", arg.text)
+		}
+		return fn, nil
+
+	case p.keyword(tok, "contains"):
+		arg := p.next()
+		if arg.kind != tokenWord {
+			return nil, p.errorf(arg, "expected IP address, got %q", arg.text)
+		}
+		fn, err := SelectContains(arg.text)
+		if err != nil {
+			return nil, p.errorf(arg, "expected IP address, got %q", arg.text)
+		}
+		return fn, nil
+
+	case p.keyword(tok, "name"):
+		return p.parseName()
+	}
+
+	return nil, p.errorf(tok, "unexpected %q", tok.text)
+}
+
+func (p *queryParser) parseComparison() (string, int, error) {
+	op := p.next()
+	switch op.text {
+	case "=", "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return "", 0, p.errorf(op, "expected comparison, got %q", op.text)
+	}
+
+	arg := p.next()
+	n, err := strconv.Atoi(strings.TrimPrefix(arg.text, "/"))
+	if err != nil || arg.kind != tokenWord {
+		return "", 0, p.errorf(arg, "expected number, got %q", arg.text)
+	}
+
+	return op.text, n, nil
+}
+
+func (p *queryParser) parseName() (func(s *Subnet) bool, error) {
+	op := p.next()
+	arg := p.next()
+	if arg.kind != tokenWord && arg.kind != tokenString {
+		return nil, p.errorf(arg, "expected name, got %q", arg.text)
+	}
+
+	switch op.text {
+	case "=", "==":
+		return func(s *Subnet) bool { return s.Reservation() != "" && s.Reservation() == arg.text }, nil
+	case "!=":
+		return func(s *Subnet) bool { return s.Reservation() != "" && s.Reservation() != arg.text }, nil
+	case "~":
+		re, err := regexp.Compile(arg.text)
+		if err != nil {
+			return nil, p.errorf(arg, "invalid regular expression %q", arg.text)
+		}
+		return SelectNameMatches(re), nil
+	}
+
+	return nil, p.errorf(op, "expected =, != or ~, got %q", op.text)
+}
+
+// sizeComparison maps a size comparison onto SelectSizeBetween
+func sizeComparison(op string, n int) func(s *Subnet) bool {
+	switch op {
+	case "<":
+		return SelectSizeBetween(0, n-1)
+	case "<=":
+		return SelectSizeBetween(0, n)
+	case ">":
+		return SelectSizeBetween(n+1, 32)
+	case ">=":
+		return SelectSizeBetween(n, 32)
+	case "!=":
+		return Not(SelectSizeBetween(n, n))
+	}
+	return SelectSizeBetween(n, n)
+}
+
+func compare(op string, a, b int) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "!=":
+		return a != b
+	}
+	return a == b
+}
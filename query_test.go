@@ -0,0 +1,69 @@
+package subnetcalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseQuery(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	web, err := s.AddReservation("10.0.0.0/24", "web-prod")
+	assert.NoError(t, err)
+	db, err := s.AddReservation("10.0.1.0/26", "db-prod")
+	assert.NoError(t, err)
+	k8s, err := s.AddReservation("10.0.8.0/22", "k8s-dev")
+	assert.NoError(t, err)
+	webDev, err := s.AddReservation("10.0.12.0/24", "web-dev")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		query string
+		want  []*Subnet
+	}{
+		{"reserved and size>=24 and within 10.0.8.0/21", []*Subnet{webDev}},
+		{"reserved and (size = 22 or size > 24)", []*Subnet{db, k8s}},
+		{"RESERVED AND NOT size != 24", []*Subnet{web, webDev}},
+		{`name ~ "^web-"`, []*Subnet{web, webDev}},
+		{"name = db-prod or name == \"k8s-dev\"", []*Subnet{db, k8s}},
+		{"reserved and contains 10.0.1.17", []*Subnet{db}},
+		{"depth <= 1", []*Subnet{s, s.low(), s.high()}},
+		{"available and size = /17", []*Subnet{s.high()}},
+		{"reserved and size < 24 and name != web-dev", []*Subnet{k8s}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filter, err := ParseQuery(tt.query)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, s.Collect(filter))
+			}
+		})
+	}
+}
+
+func Test_ParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{"", `invalid query: unexpected "end of query" at position 0`},
+		{"reserved and", `invalid query: unexpected "end of query" at position 12`},
+		{"size >= big", `invalid query: expected number, got "big" at position 8`},
+		{"size ~ 24", `invalid query: expected comparison, got "~" at position 5`},
+		{"within 10.0.0.0/40", `invalid query: expected CIDR, got "10.0.0.0/40" at position 7`},
+		{"contains 10.0.0", `invalid query: expected IP address, got "10.0.0" at position 9`},
+		{`name ~ "("`, `invalid query: invalid regular expression "(" at position 7`},
+		{`name ~ "web`, `invalid query: unterminated string at position 7`},
+		{"(reserved", `invalid query: expected ")", got "end of query" at position 9`},
+		{"reserved available", `invalid query: unexpected "available" at position 9`},
+		{"free", `invalid query: unexpected "free" at position 0`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			assert.ErrorIs(t, err, ErrInvalidQuery)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
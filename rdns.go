@@ -0,0 +1,163 @@
+package subnetcalc
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+)
+
+// ZoneOptions controls the records written by WriteReverseZones
+type ZoneOptions struct {
+	Domain string // Domain appended to host reservation names not ending with a dot
+	TTL    int    // TTL of the records, left out if zero
+}
+
+// ReverseZones returns the in-addr.arpa zones covering the subnet. Subnets up to /24 not on an octet boundary
+// are covered by several zones, while subnets longer than /24 get a RFC 2317 classless zone like
+// 0/26.2.0.10.in-addr.arpa.
+func (s *Subnet) ReverseZones() []string {
+	zones, _ := ReverseZonesForCIDR(s.CIDR())
+	return zones
+}
+
+// ReverseZonesForCIDR returns the reverse zones covering an IPv4 or IPv6 CIDR range. IPv6 zones are in
+// ip6.arpa, one per nibble aligned prefix.
+func ReverseZonesForCIDR(cidr string) ([]string, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, &ParseError{Input: cidr, Err: err}
+	}
+	bits, _ := ipNet.Mask.Size()
+
+	if ip4 := ipNet.IP.To4(); ip4 != nil {
+		if bits > 24 {
+			return []string{fmt.Sprintf("%d/%d.%d.%d.%d.in-addr.arpa", ip4[3], bits, ip4[2], ip4[1], ip4[0])}, nil
+		}
+		return reverseZones(ip4, bits, 8, func(b []byte, i int) string { return fmt.Sprint(b[i]) }, "in-addr.arpa"), nil
+	}
+
+	return reverseZones(ipNet.IP, bits, 4, func(b []byte, i int) string {
+		if i%2 == 0 {
+			return fmt.Sprintf("%x", b[i/2]>>4)
+		}
+		return fmt.Sprintf("%x", b[i/2]&0xf)
+	}, "ip6.arpa"), nil
+}
+
+// reverseZones enumerates the zones aligned to the label width covering the prefix of ip, with label returning
+// the i'th label of an address
+func reverseZones(ip net.IP, bits, width int, label func(b []byte, i int) string, suffix string) []string {
+	labels := (bits + width - 1) / width
+	count := 1 << (labels*width - bits)
+
+	zones := make([]string, 0, count)
+	for n := 0; n < count; n++ {
+		addr := append(net.IP{}, ip...)
+		addAt(addr, labels*width, n)
+
+		parts := make([]string, 0, labels+1)
+		for i := labels - 1; i >= 0; i-- {
+			parts = append(parts, label(addr, i))
+		}
+		zones = append(zones, strings.Join(append(parts, suffix), "."))
+	}
+	return zones
+}
+
+// addAt adds n to the address, counting in units of the bit at position bits
+func addAt(addr net.IP, bits, n int) {
+	carry := n << ((8 - bits%8) % 8)
+	for i := (bits+7)/8 - 1; i >= 0 && carry > 0; i-- {
+		sum := int(addr[i]) + carry
+		addr[i] = byte(sum)
+		carry = sum >> 8
+	}
+}
+
+// WriteReverseZones writes BIND zone file snippets with PTR records for the host (/32) reservations within the
+// subnet, one $ORIGIN section per reverse zone
+func (s *Subnet) WriteReverseZones(w io.Writer, opts ZoneOptions) error {
+	var b strings.Builder
+	for _, zone := range s.ReverseZones() {
+		fmt.Fprintf(&b, "$ORIGIN %s.\n", zone)
+
+		for host := range s.Reserved() {
+			if host.Size() != 32 || !strings.HasSuffix("."+zone, reverseSuffix(host.network(), s.Size())) {
+				continue
+			}
+			fmt.Fprintf(&b, "%s\t%sIN\tPTR\t%s\n", reverseOwner(host.network(), s.Size()), ttlField(opts.TTL), fqdn(host.Reservation(), opts.Domain))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteClasslessDelegation writes the records delegating the RFC 2317 classless zone of a subnet longer than /24
+// from its parent /24 zone, i.e. NS records for the zone and a CNAME for each address
+func (s *Subnet) WriteClasslessDelegation(w io.Writer, nameservers []string, opts ZoneOptions) error {
+	if s.Size() <= 24 {
+		return &SubnetError{CIDR: s.CIDR(), Err: ErrInvalidSize}
+	}
+	zone := s.ReverseZones()[0]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ORIGIN %s\n", reverseSuffix(s.network(), 24)[1:]+".")
+	label := strings.TrimSuffix(zone, reverseSuffix(s.network(), 24))
+	for _, ns := range nameservers {
+		fmt.Fprintf(&b, "%s\t%sIN\tNS\t%s\n", label, ttlField(opts.TTL), fqdn(ns, opts.Domain))
+	}
+	for addr := s.network(); addr <= s.broadcast(); addr++ {
+		fmt.Fprintf(&b, "%d\t%sIN\tCNAME\t%d.%s.\n", addr&0xff, ttlField(opts.TTL), addr&0xff, zone)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// reverseOwner returns the owner name of an address relative to the reverse zone of a subnet of the given size
+func reverseOwner(addr, size int) string {
+	octets := reverseOctets(addr)
+	if size > 24 {
+		return octets[0]
+	}
+	return strings.Join(octets[:4-(size+7)/8], ".")
+}
+
+// reverseSuffix returns the part of the reverse name of an address identifying the zone of a subnet of the
+// given size, starting with a dot
+func reverseSuffix(addr, size int) string {
+	if size > 24 {
+		size = 24
+	}
+	octets := reverseOctets(addr)
+	return "." + strings.Join(octets[4-(size+7)/8:], ".") + ".in-addr.arpa"
+}
+
+func reverseOctets(addr int) []string {
+	return []string{
+		fmt.Sprint(addr & 0xff),
+		fmt.Sprint((addr >> 8) & 0xff),
+		fmt.Sprint((addr >> 16) & 0xff),
+		fmt.Sprint((addr >> 24) & 0xff),
+	}
+}
+
+func ttlField(ttl int) string {
+	if ttl == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d\t", ttl)
+}
+
+// fqdn returns the name with the domain appended, unless it is already fully qualified
+func fqdn(name, domain string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	if domain != "" {
+		name += "." + strings.TrimSuffix(domain, ".")
+	}
+	return name + "."
+}
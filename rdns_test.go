@@ -0,0 +1,98 @@
+package subnetcalc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReverseZones(t *testing.T) {
+	for _, tc := range []struct {
+		cidr  string
+		zones []string
+	}{
+		{"10.0.0.0/8", []string{"10.in-addr.arpa"}},
+		{"10.1.0.0/16", []string{"1.10.in-addr.arpa"}},
+		{"10.1.2.0/24", []string{"2.1.10.in-addr.arpa"}},
+		{"10.1.4.0/23", []string{"4.1.10.in-addr.arpa", "5.1.10.in-addr.arpa"}},
+		{"10.0.16.0/20", []string{
+			"16.0.10.in-addr.arpa", "17.0.10.in-addr.arpa", "18.0.10.in-addr.arpa", "19.0.10.in-addr.arpa",
+			"20.0.10.in-addr.arpa", "21.0.10.in-addr.arpa", "22.0.10.in-addr.arpa", "23.0.10.in-addr.arpa",
+			"24.0.10.in-addr.arpa", "25.0.10.in-addr.arpa", "26.0.10.in-addr.arpa", "27.0.10.in-addr.arpa",
+			"28.0.10.in-addr.arpa", "29.0.10.in-addr.arpa", "30.0.10.in-addr.arpa", "31.0.10.in-addr.arpa",
+		}},
+		{"10.1.2.64/26", []string{"64/26.2.1.10.in-addr.arpa"}},
+		{"10.1.2.7/32", []string{"7/32.2.1.10.in-addr.arpa"}},
+	} {
+		t.Run(tc.cidr, func(t *testing.T) {
+			s, err := Parse(tc.cidr)
+			assert.NoError(t, err, "parse should return no error")
+			assert.Equal(t, tc.zones, s.ReverseZones())
+		})
+	}
+}
+
+func Test_ReverseZonesForCIDR(t *testing.T) {
+	zones, err := ReverseZonesForCIDR("2001:db8::/32")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"8.b.d.0.1.0.0.2.ip6.arpa"}, zones)
+
+	zones, err = ReverseZonesForCIDR("2001:db8:ab00::/39")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.a.8.b.d.0.1.0.0.2.ip6.arpa", "b.a.8.b.d.0.1.0.0.2.ip6.arpa"}, zones)
+
+	_, err = ReverseZonesForCIDR("not a cidr")
+	assert.ErrorIs(t, err, ErrCouldNotParse)
+}
+
+func Test_WriteReverseZones(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	lan, err := s.AddReservation("10.0.2.0/23", "lan")
+	assert.NoError(t, err)
+	_, err = lan.AddReservation("10.0.2.1/32", "gw")
+	assert.NoError(t, err)
+	_, err = lan.AddReservation("10.0.3.17/32", "printer.example.org.")
+	assert.NoError(t, err)
+
+	var b bytes.Buffer
+	assert.NoError(t, lan.WriteReverseZones(&b, ZoneOptions{Domain: "example.com", TTL: 3600}))
+	assert.Equal(t, `$ORIGIN 2.0.10.in-addr.arpa.
+1	3600	IN	PTR	gw.example.com.
+$ORIGIN 3.0.10.in-addr.arpa.
+17	3600	IN	PTR	printer.example.org.
+`, b.String())
+
+	dmz, err := s.AddReservation("10.0.4.64/28", "dmz")
+	assert.NoError(t, err)
+	_, err = dmz.AddReservation("10.0.4.66/32", "www")
+	assert.NoError(t, err)
+
+	b.Reset()
+	assert.NoError(t, dmz.WriteReverseZones(&b, ZoneOptions{Domain: "example.com."}))
+	assert.Equal(t, `$ORIGIN 64/28.4.0.10.in-addr.arpa.
+66	IN	PTR	www.example.com.
+`, b.String())
+}
+
+func Test_WriteClasslessDelegation(t *testing.T) {
+	s, err := Parse("10.0.4.64/30")
+	assert.NoError(t, err, "parse should return no error")
+
+	var b bytes.Buffer
+	assert.NoError(t, s.WriteClasslessDelegation(&b, []string{"ns1", "ns2.example.net."}, ZoneOptions{Domain: "example.com"}))
+	assert.Equal(t, `$ORIGIN 4.0.10.in-addr.arpa.
+64/30	IN	NS	ns1.example.com.
+64/30	IN	NS	ns2.example.net.
+64	IN	CNAME	64.64/30.4.0.10.in-addr.arpa.
+65	IN	CNAME	65.64/30.4.0.10.in-addr.arpa.
+66	IN	CNAME	66.64/30.4.0.10.in-addr.arpa.
+67	IN	CNAME	67.64/30.4.0.10.in-addr.arpa.
+`, b.String())
+
+	s, err = Parse("10.0.4.0/24")
+	assert.NoError(t, err, "parse should return no error")
+	assert.ErrorIs(t, s.WriteClasslessDelegation(&b, nil, ZoneOptions{}), ErrInvalidSize)
+}
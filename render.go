@@ -0,0 +1,131 @@
+package subnetcalc
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+var ErrUnknownTemplate = errors.New("unknown template")
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// RenderData is the data passed to templates
+type RenderData struct {
+	Root         string              // CIDR of the tree
+	Reservations []RenderReservation // Reserved subnets in address order
+	Vars         map[string]string   // Variables given to Render
+}
+
+// RenderReservation describes a reserved subnet in templates
+type RenderReservation struct {
+	CIDR    string
+	Name    string
+	Network string
+	Netmask string
+	FirstIP string
+	LastIP  string
+	Size    int
+}
+
+// Renderer renders reservations as configuration snippets using text/template. The built-in templates are
+//
+//	ip-route             Linux ip route commands, using the variables via and dev
+//	nftables             an nftables interval set named by the variable set
+//	cisco-prefix-list    a Cisco IOS prefix-list named by the variable name
+//	juniper-prefix-list  a Junos prefix-list named by the variable name
+//	k8s-ipblock          Kubernetes NetworkPolicy ipBlock peers
+//
+// Templates can use the functions default, which returns its first argument if the second is empty, and seq,
+// which returns the prefix-list sequence number of an index.
+type Renderer struct {
+	templates *template.Template
+}
+
+// NewRenderer returns a renderer with the built-in templates
+func NewRenderer() *Renderer {
+	r := &Renderer{
+		templates: template.New("").Option("missingkey=zero").Funcs(template.FuncMap{
+			"default": func(def, value string) string {
+				if value == "" {
+					return def
+				}
+				return value
+			},
+			"seq": func(i int) int { return (i + 1) * 5 },
+		}),
+	}
+
+	entries, _ := builtinTemplates.ReadDir("templates")
+	for _, e := range entries {
+		data, _ := builtinTemplates.ReadFile("templates/" + e.Name())
+		template.Must(r.templates.New(strings.TrimSuffix(e.Name(), ".tmpl")).Parse(string(data)))
+	}
+
+	return r
+}
+
+// Parse adds a template, replacing any template with the same name
+func (r *Renderer) Parse(name, text string) error {
+	_, err := r.templates.New(name).Parse(text)
+	return err
+}
+
+// ParseFiles adds templates from files, named by the file name without extension
+func (r *Renderer) ParseFiles(paths ...string) error {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err = r.Parse(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Templates returns the names of the available templates
+func (r *Renderer) Templates() []string {
+	var names []string
+	for _, t := range r.templates.Templates() {
+		if t.Name() != "" {
+			names = append(names, t.Name())
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Render renders the reserved subnets of the tree, optionally narrowed by filters, with the named template
+func (r *Renderer) Render(w io.Writer, name string, s *Subnet, vars map[string]string, filterFunc ...func(s *Subnet) bool) error {
+	t := r.templates.Lookup(name)
+	if t == nil || name == "" {
+		return fmt.Errorf("%w: %q", ErrUnknownTemplate, name)
+	}
+
+	data := RenderData{Root: s.CIDR(), Vars: vars}
+	if data.Vars == nil {
+		data.Vars = map[string]string{}
+	}
+	for _, sn := range s.Collect(append([]func(s *Subnet) bool{SelectReserved()}, filterFunc...)...) {
+		data.Reservations = append(data.Reservations, RenderReservation{
+			CIDR:    sn.CIDR(),
+			Name:    sn.Reservation(),
+			Network: inetNToA(sn.network()),
+			Netmask: inetNToA(inetSubnetNetmask(sn.Size())),
+			FirstIP: sn.FirstIP(),
+			LastIP:  sn.LastIP(),
+			Size:    sn.Size(),
+		})
+	}
+
+	return t.Execute(w, data)
+}
@@ -0,0 +1,122 @@
+package subnetcalc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RenderBuiltin(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.0.0/28", "web-lb")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.4.0/22", "db")
+	assert.NoError(t, err)
+	r := NewRenderer()
+
+	assert.Equal(t, []string{"cisco-prefix-list", "ip-route", "juniper-prefix-list", "k8s-ipblock", "nftables"}, r.Templates())
+
+	for _, tc := range []struct {
+		name     string
+		vars     map[string]string
+		expected string
+	}{
+		{"ip-route", map[string]string{"via": "192.168.1.1"}, `ip route add 10.0.0.0/24 via 192.168.1.1
+ip route add 10.0.0.0/28 via 192.168.1.1
+ip route add 10.0.4.0/22 via 192.168.1.1
+`},
+		{"ip-route", map[string]string{"dev": "eth1"}, `ip route add 10.0.0.0/24 dev eth1
+ip route add 10.0.0.0/28 dev eth1
+ip route add 10.0.4.0/22 dev eth1
+`},
+		{"nftables", nil, `set reserved {
+	type ipv4_addr
+	flags interval
+	auto-merge
+	elements = {
+		10.0.0.0/24,
+		10.0.0.0/28,
+		10.0.4.0/22
+	}
+}
+`},
+		{"cisco-prefix-list", map[string]string{"name": "LAN"}, `ip prefix-list LAN seq 5 permit 10.0.0.0/24
+ip prefix-list LAN seq 10 permit 10.0.0.0/28
+ip prefix-list LAN seq 15 permit 10.0.4.0/22
+`},
+		{"juniper-prefix-list", nil, `policy-options {
+    prefix-list reserved {
+        10.0.0.0/24;
+        10.0.0.0/28;
+        10.0.4.0/22;
+    }
+}
+`},
+		{"k8s-ipblock", nil, `# web
+- ipBlock:
+    cidr: 10.0.0.0/24
+# web-lb
+- ipBlock:
+    cidr: 10.0.0.0/28
+# db
+- ipBlock:
+    cidr: 10.0.4.0/22
+`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			assert.NoError(t, r.Render(&b, tc.name, s, tc.vars))
+			assert.Equal(t, tc.expected, b.String())
+		})
+	}
+}
+
+func Test_RenderFilter(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.4.0/22", "db")
+	assert.NoError(t, err)
+
+	var b bytes.Buffer
+	assert.NoError(t, NewRenderer().Render(&b, "nftables", s, map[string]string{"set": "db"}, SelectWithSize(22)))
+	assert.Equal(t, "set db {\n\ttype ipv4_addr\n\tflags interval\n\tauto-merge\n\telements = {\n\t\t10.0.4.0/22\n\t}\n}\n", b.String())
+
+	b.Reset()
+	assert.NoError(t, NewRenderer().Render(&b, "nftables", s, nil, SelectWithSize(8)))
+	assert.Equal(t, "set reserved {\n\ttype ipv4_addr\n\tflags interval\n\tauto-merge\n}\n", b.String())
+}
+
+func Test_RenderOverride(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	_, err = s.AddReservation("10.0.0.0/24", "web")
+	assert.NoError(t, err)
+	_, err = s.AddReservation("10.0.4.0/22", "db")
+	assert.NoError(t, err)
+	r := NewRenderer()
+
+	assert.NoError(t, r.Parse("ip-route", "{{range .Reservations}}route {{.Network}} {{.Netmask}} {{.Name}}\n{{end}}"))
+
+	path := filepath.Join(t.TempDir(), "hosts.tmpl")
+	assert.NoError(t, os.WriteFile(path, []byte("{{range .Reservations}}{{.FirstIP}}-{{.LastIP}} /{{.Size}} {{$.Root}}\n{{end}}"), 0o644))
+	assert.NoError(t, r.ParseFiles(path))
+
+	var b bytes.Buffer
+	assert.NoError(t, r.Render(&b, "ip-route", s, nil))
+	assert.Equal(t, "route 10.0.0.0 255.255.255.0 web\nroute 10.0.4.0 255.255.252.0 db\n", b.String())
+
+	b.Reset()
+	assert.NoError(t, r.Render(&b, "hosts", s, nil))
+	assert.Equal(t, "10.0.0.1-10.0.0.254 /24 10.0.0.0/16\n10.0.4.1-10.0.7.254 /22 10.0.0.0/16\n", b.String())
+
+	assert.Error(t, r.Parse("broken", "{{range}}"))
+	assert.ErrorIs(t, r.Render(&b, "missing", s, nil), ErrUnknownTemplate)
+}
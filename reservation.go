@@ -0,0 +1,151 @@
+package subnetcalc
+
+import (
+	"errors"
+)
+
+var ErrEmptyName = errors.New("reservation name is empty")
+var ErrCannotResize = errors.New("subnet cannot be resized")
+
+// Rename changes the name of an existing reservation
+func (s *Subnet) Rename(newName string) error {
+	if !s.reserved {
+		return &SubnetError{CIDR: s.CIDR(), Err: ErrNotReserved}
+	}
+	if newName == "" {
+		return &SubnetError{CIDR: s.CIDR(), Err: ErrEmptyName}
+	}
+	if newName == s.Reservation() {
+		return nil
+	}
+
+	if err := s.checkPolicies(newName); err != nil {
+		return err
+	}
+
+	st := s.state()
+	oldName := st.reservations[s]
+	st.removeName(s, oldName)
+	st.reservations[s] = newName
+	st.addName(s, newName)
+
+	s.notify(Event{Type: EventRenamed, Name: newName, OldName: oldName, Pool: s.pool})
+
+	return nil
+}
+
+// Move moves an existing reservation to the subnet toCidr in the same tree, returning the new reserved subnet.
+// Nothing is changed if the destination overlaps any other reservation.
+func (s *Subnet) Move(toCidr string) (*Subnet, error) {
+	if !s.reserved {
+		return nil, &SubnetError{CIDR: s.CIDR(), Err: ErrNotReserved}
+	}
+
+	dest, err := s.root().findCIDR(toCidr)
+	if err != nil {
+		return nil, err
+	}
+	if dest == s {
+		return s, nil
+	}
+
+	if err = s.moveReservation(dest); err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+// Grow doubles an existing reservation into its parent subnet if the other half is free, returning the new
+// reserved subnet
+func (s *Subnet) Grow() (*Subnet, error) {
+	if !s.reserved {
+		return nil, &SubnetError{CIDR: s.CIDR(), Err: ErrNotReserved}
+	}
+	parent := s.parent()
+	if parent == nil {
+		return nil, &SubnetError{CIDR: s.CIDR(), Err: ErrCannotResize}
+	}
+
+	if err := s.moveReservation(parent); err != nil {
+		return nil, err
+	}
+
+	parent.notify(Event{Type: EventMerged, Name: parent.Reservation(), OldCIDR: s.CIDR(), Pool: parent.pool})
+	return parent, nil
+}
+
+// Shrink reduces an existing reservation to the first subnet of the given size within it, releasing the rest,
+// and returns the new reserved subnet
+func (s *Subnet) Shrink(newSize int) (*Subnet, error) {
+	if !s.reserved {
+		return nil, &SubnetError{CIDR: s.CIDR(), Err: ErrNotReserved}
+	}
+	if newSize <= s.Size() || newSize > 32 {
+		return nil, &SubnetError{CIDR: s.CIDR(), Err: ErrCannotResize}
+	}
+
+	target := s
+	for target.Size() < newSize {
+		_ = target.divide()
+		target = target.low()
+	}
+
+	if err := s.moveReservation(target); err != nil {
+		return nil, err
+	}
+
+	target.notify(Event{Type: EventDivided, Name: target.Reservation(), OldCIDR: s.CIDR(), Pool: target.pool})
+	return target, nil
+}
+
+// moveReservation moves the reservation of the subnet to dest, after checking dest for conflicts and policies.
+// A pool with allocations can only be moved to a subnet containing it.
+func (s *Subnet) moveReservation(dest *Subnet) error {
+	if s.pool && s.subReservations > 0 && !s.within(dest) {
+		return &SubnetError{CIDR: s.CIDR(), Err: ErrPoolInUse}
+	}
+	if dest.reserved {
+		return &ConflictError{CIDR: dest.CIDR(), Name: s.Reservation(), Conflict: dest, Reservation: dest.Reservation()}
+	}
+	if conflict := dest.conflict(s); conflict != nil {
+		return &ConflictError{CIDR: dest.CIDR(), Name: s.Reservation(), Conflict: conflict, Reservation: conflict.Reservation()}
+	}
+	if err := dest.checkPolicies(s.Reservation()); err != nil {
+		return err
+	}
+
+	name, pool := s.Reservation(), s.pool
+	s.clearReservation()
+	dest.pool = pool
+	dest.setReservation(name)
+
+	return nil
+}
+
+// conflict returns the closest reservation containing the subnet or else the first reservation within it,
+// ignoring the subnet ignore, the reservations within it and the pools it is allocated from that strictly
+// contain the subnet, or nil if there is none
+func (s *Subnet) conflict(ignore *Subnet) *Subnet {
+	var container, within *Subnet
+	s.state().reserved.overlapping(uint32(s.network()), s.Size(), func(n *prefixNode[*Subnet]) bool {
+		p := n.value
+		switch {
+		case p == ignore:
+		case p.Size() <= s.Size():
+			if !(p.pool && p.Size() < s.Size() && ignore.within(p)) {
+				container = p
+			}
+		case container != nil:
+			return false
+		case !p.within(ignore):
+			within = p
+			return false
+		}
+		return true
+	})
+
+	if container != nil {
+		return container
+	}
+	return within
+}
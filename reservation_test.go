@@ -0,0 +1,216 @@
+package subnetcalc
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Rename(t *testing.T) {
+	t.Run("Ok", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.0.0/24", "old")
+		assert.NoError(t, err)
+
+		assert.NoError(t, sn.Rename("new"))
+		assert.Equal(t, "new", sn.Reservation())
+		assert.Empty(t, s.FindByName("old"))
+		assert.Equal(t, []*Subnet{sn}, s.FindByName("new"))
+		assert.Equal(t, 1, s.subReservations)
+
+		assert.NoError(t, sn.Rename("new"))
+	})
+
+	t.Run("Not reserved", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		assert.ErrorIs(t, s.Rename("new"), ErrNotReserved)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.0.0/24", "old")
+		assert.NoError(t, err)
+		assert.ErrorIs(t, sn.Rename(""), ErrEmptyName)
+	})
+
+	t.Run("Policy", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		s.AddPolicy(NamePattern(regexp.MustCompile(`^[a-z]+$`)))
+
+		sn, err := s.AddReservation("10.0.0.0/24", "old")
+		assert.NoError(t, err)
+		assert.ErrorIs(t, sn.Rename("New"), ErrPolicyViolation)
+		assert.Equal(t, "old", sn.Reservation())
+	})
+}
+
+func Test_Move(t *testing.T) {
+	t.Run("Ok", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.0.0/24", "app")
+		assert.NoError(t, err)
+
+		moved, err := sn.Move("10.0.8.0/23")
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.8.0/23", moved.CIDR())
+		assert.Equal(t, "app", moved.Reservation())
+		assert.Equal(t, "", sn.Reservation())
+		assert.Equal(t, []*Subnet{moved}, s.FindByName("app"))
+		assert.Equal(t, 1, s.subReservations)
+		assert.Equal(t, 0, sn.parent().subReservations)
+	})
+
+	t.Run("Into itself", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.0.0/24", "app")
+		assert.NoError(t, err)
+
+		moved, err := sn.Move("10.0.0.128/25")
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.0.128/25", moved.CIDR())
+		assert.Equal(t, 1, s.subReservations)
+		assert.Equal(t, 1, sn.subReservations)
+	})
+
+	t.Run("Overlapping", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.0.0/24", "app")
+		assert.NoError(t, err)
+		_, err = s.AddReservation("10.0.9.0/24", "db")
+		assert.NoError(t, err)
+		_, err = s.AddReservation("10.0.16.0/20", "k8s")
+		assert.NoError(t, err)
+
+		_, err = sn.Move("10.0.8.0/23")
+		assert.ErrorIs(t, err, ErrAlreadyReserved)
+		assert.EqualError(t, err, `subnet is already reserved: 10.0.8.0/23 requested as "app" overlaps 10.0.9.0/24 reserved as "db"`)
+
+		_, err = sn.Move("10.0.17.0/24")
+		assert.ErrorIs(t, err, ErrAlreadyReserved)
+
+		assert.Equal(t, "app", sn.Reservation())
+		assert.Equal(t, 3, s.subReservations)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		_, err = s.Move("10.0.1.0/24")
+		assert.ErrorIs(t, err, ErrNotReserved)
+
+		sn, err := s.AddReservation("10.0.0.0/24", "app")
+		assert.NoError(t, err)
+
+		_, err = sn.Move("10.1.0.0/24")
+		assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+
+		_, err = sn.Move("bogus")
+		assert.ErrorIs(t, err, ErrCouldNotParse)
+
+		s.AddPolicy(MaxSize(24))
+		_, err = sn.Move("10.0.1.0/25")
+		assert.ErrorIs(t, err, ErrPolicyViolation)
+		assert.Equal(t, "app", sn.Reservation())
+	})
+}
+
+func Test_Grow(t *testing.T) {
+	t.Run("Ok", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.1.128/25", "team")
+		assert.NoError(t, err)
+		_, err = s.AddReservation("10.0.1.192/28", "team-lb")
+		assert.NoError(t, err)
+
+		grown, err := sn.Grow()
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.1.0/24", grown.CIDR())
+		assert.Equal(t, "team", grown.Reservation())
+		assert.Equal(t, "", sn.Reservation())
+		assert.Equal(t, 1, grown.subReservations)
+		assert.Equal(t, 2, s.subReservations)
+	})
+
+	t.Run("Buddy taken", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.1.128/25", "team")
+		assert.NoError(t, err)
+		_, err = s.AddReservation("10.0.1.0/28", "other")
+		assert.NoError(t, err)
+
+		_, err = sn.Grow()
+		assert.ErrorIs(t, err, ErrAlreadyReserved)
+		assert.Equal(t, "team", sn.Reservation())
+	})
+
+	t.Run("Root", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		assert.NoError(t, s.Reserve("all"))
+
+		_, err = s.Grow()
+		assert.ErrorIs(t, err, ErrCannotResize)
+	})
+}
+
+func Test_Shrink(t *testing.T) {
+	t.Run("Ok", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.4.0/22", "team")
+		assert.NoError(t, err)
+
+		shrunk, err := sn.Shrink(23)
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.4.0/23", shrunk.CIDR())
+		assert.Equal(t, "team", shrunk.Reservation())
+
+		free, err := s.FindFree(23)
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.0.0/23", free.CIDR())
+		assert.NoError(t, free.Reserve("a"))
+		free, err = s.FindFree(23)
+		assert.NoError(t, err)
+		assert.NoError(t, free.Reserve("b"))
+		free, err = s.FindFree(23)
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.6.0/23", free.CIDR(), "released half is available")
+	})
+
+	t.Run("Beyond /32", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		sn, err := s.AddReservation("10.0.0.0/30", "link")
+		assert.NoError(t, err)
+
+		host, err := sn.Shrink(32)
+		assert.NoError(t, err)
+		assert.Equal(t, "10.0.0.0/32", host.CIDR())
+
+		_, err = host.Shrink(33)
+		assert.ErrorIs(t, err, ErrCannotResize)
+		_, err = host.Shrink(24)
+		assert.ErrorIs(t, err, ErrCannotResize)
+	})
+}
@@ -0,0 +1,83 @@
+package subnetcalc
+
+import (
+	"regexp"
+)
+
+// And is a collector helper function selecting subnets matching all the given selectors
+func And(filterFunc ...func(s *Subnet) bool) func(s *Subnet) bool {
+	return func(s *Subnet) bool {
+		for _, fn := range filterFunc {
+			if !fn(s) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or is a collector helper function selecting subnets matching any of the given selectors
+func Or(filterFunc ...func(s *Subnet) bool) func(s *Subnet) bool {
+	return func(s *Subnet) bool {
+		for _, fn := range filterFunc {
+			if fn(s) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not is a collector helper function selecting subnets not matching the given selector
+func Not(filterFunc func(s *Subnet) bool) func(s *Subnet) bool {
+	return func(s *Subnet) bool {
+		return !filterFunc(s)
+	}
+}
+
+// SelectSizeBetween is a collector helper function selecting subnets with a size from minSize to maxSize, inclusive
+func SelectSizeBetween(minSize, maxSize int) func(s *Subnet) bool {
+	return func(s *Subnet) bool {
+		return s.Size() >= minSize && s.Size() <= maxSize
+	}
+}
+
+// SelectWithin is a collector helper function selecting subnets contained in the given CIDR range
+func SelectWithin(cidr string) (func(s *Subnet) bool, error) {
+	c, err := toCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	outer := c.subnet()
+
+	return func(s *Subnet) bool {
+		return s.within(outer)
+	}, nil
+}
+
+// SelectContains is a collector helper function selecting subnets containing the given IP address
+func SelectContains(ip string) (func(s *Subnet) bool, error) {
+	addr, err := toIP(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(s *Subnet) bool {
+		return s.contains(addr)
+	}, nil
+}
+
+// SelectNameMatches is a collector helper function selecting reserved subnets with a name matching the regular
+// expression
+func SelectNameMatches(re *regexp.Regexp) func(s *Subnet) bool {
+	return func(s *Subnet) bool {
+		return s.Reservation() != "" && re.MatchString(s.Reservation())
+	}
+}
+
+// SelectDepth is a collector helper function selecting subnets at the given depth below the root of the tree
+func SelectDepth(depth int) func(s *Subnet) bool {
+	return func(s *Subnet) bool {
+		return s.depth() == depth
+	}
+}
@@ -0,0 +1,67 @@
+package subnetcalc
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Selectors(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+	web, err := s.AddReservation("10.0.0.0/24", "web-prod")
+	assert.NoError(t, err)
+	db, err := s.AddReservation("10.0.1.0/26", "db-prod")
+	assert.NoError(t, err)
+	k8s, err := s.AddReservation("10.0.8.0/22", "k8s-dev")
+	assert.NoError(t, err)
+	webDev, err := s.AddReservation("10.0.12.0/24", "web-dev")
+	assert.NoError(t, err)
+
+	t.Run("Or", func(t *testing.T) {
+		within, err := SelectWithin("10.0.0.0/21")
+		assert.NoError(t, err)
+		assert.Equal(t, []*Subnet{web, db}, s.Collect(SelectReserved(), Or(SelectWithSize(24), SelectWithSize(26)), within))
+	})
+
+	t.Run("Not", func(t *testing.T) {
+		assert.Equal(t, []*Subnet{db, k8s}, s.Collect(SelectReserved(), Not(SelectWithSize(24))))
+	})
+
+	t.Run("And", func(t *testing.T) {
+		assert.Equal(t, s.Collect(SelectReserved(), SelectWithSize(24)), s.Collect(And(SelectReserved(), SelectWithSize(24))))
+	})
+
+	t.Run("SizeBetween", func(t *testing.T) {
+		assert.Equal(t, []*Subnet{web, k8s, webDev}, s.Collect(SelectReserved(), SelectSizeBetween(22, 24)))
+	})
+
+	t.Run("Within", func(t *testing.T) {
+		within, err := SelectWithin("10.0.8.0/21")
+		assert.NoError(t, err)
+		assert.Equal(t, []*Subnet{k8s, webDev}, s.Collect(SelectReserved(), within))
+
+		_, err = SelectWithin("bogus")
+		assert.ErrorIs(t, err, ErrCouldNotParse)
+	})
+
+	t.Run("Contains", func(t *testing.T) {
+		contains, err := SelectContains("10.0.1.17")
+		assert.NoError(t, err)
+		assert.Equal(t, []*Subnet{db}, s.Collect(SelectReserved(), contains))
+		assert.Len(t, s.Collect(contains), 16)
+
+		_, err = SelectContains("bogus")
+		assert.ErrorIs(t, err, ErrCouldNotParse)
+	})
+
+	t.Run("NameMatches", func(t *testing.T) {
+		assert.Equal(t, []*Subnet{web, db}, s.Collect(SelectNameMatches(regexp.MustCompile(`-prod$`))))
+	})
+
+	t.Run("Depth", func(t *testing.T) {
+		assert.Equal(t, []*Subnet{s.low(), s.high()}, s.Collect(SelectDepth(1)))
+		assert.Equal(t, []*Subnet{s}, s.Collect(SelectDepth(0)))
+	})
+}
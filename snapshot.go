@@ -0,0 +1,57 @@
+package subnetcalc
+
+import (
+	"encoding/json"
+)
+
+type snapshot struct {
+	Root         string                `json:"root"`
+	Reservations []snapshotReservation `json:"reservations"`
+}
+
+type snapshotReservation struct {
+	CIDR string `json:"cidr"`
+	Name string `json:"name"`
+	Pool bool   `json:"pool,omitempty"`
+}
+
+// MarshalJSON encodes the subnet and its reservations, which can be restored by ParseJSON. Policies and
+// observers registered on the tree are not included.
+func (s *Subnet) MarshalJSON() ([]byte, error) {
+	snap := snapshot{
+		Root:         s.CIDR(),
+		Reservations: []snapshotReservation{},
+	}
+	for _, r := range s.sortedReservations() {
+		snap.Reservations = append(snap.Reservations, snapshotReservation{
+			CIDR: r.CIDR(),
+			Name: r.Reservation(),
+			Pool: r.IsPool(),
+		})
+	}
+
+	return json.Marshal(snap)
+}
+
+// ParseJSON will parse a subnet and its reservations encoded by MarshalJSON
+func ParseJSON(data []byte) (*Subnet, error) {
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+
+	s, err := Parse(snap.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range snap.Reservations {
+		sn, err := s.AddReservation(r.CIDR, r.Name)
+		if err != nil {
+			return nil, err
+		}
+		sn.pool = r.Pool
+	}
+
+	return s, nil
+}
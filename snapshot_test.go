@@ -0,0 +1,59 @@
+package subnetcalc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JSON(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		team, err := s.AddReservation("10.0.16.0/20", "team-x")
+		assert.NoError(t, err)
+		assert.NoError(t, team.SetPool(true))
+		_, err = team.FindFreeAndReserve(24, "team-x-a")
+		assert.NoError(t, err)
+		_, err = s.AddReservation("10.0.0.0/24", "web")
+		assert.NoError(t, err)
+
+		data, err := json.Marshal(s)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"root":"10.0.0.0/16","reservations":[
+			{"cidr":"10.0.0.0/24","name":"web"},
+			{"cidr":"10.0.16.0/20","name":"team-x","pool":true},
+			{"cidr":"10.0.16.0/24","name":"team-x-a"}]}`, string(data))
+
+		parsed, err := ParseJSON(data)
+		assert.NoError(t, err)
+
+		changes, err := Diff(s, parsed)
+		assert.NoError(t, err)
+		assert.True(t, changes.Empty())
+		assert.True(t, parsed.FindByName("team-x")[0].IsPool())
+		assert.Equal(t, 3, parsed.subReservations)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/24")
+		assert.NoError(t, err, "parse should return no error")
+
+		data, err := json.Marshal(s)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"root":"10.0.0.0/24","reservations":[]}`, string(data))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := ParseJSON([]byte(`{"root":`))
+		assert.Error(t, err)
+
+		_, err = ParseJSON([]byte(`{"root":"10.0.0.0/40"}`))
+		assert.ErrorIs(t, err, ErrCouldNotParse)
+
+		_, err = ParseJSON([]byte(`{"root":"10.0.0.0/16","reservations":[{"cidr":"10.1.0.0/24","name":"x"}]}`))
+		assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+	})
+}
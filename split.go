@@ -0,0 +1,67 @@
+package subnetcalc
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+)
+
+var ErrInvalidSize = errors.New("invalid subnet size")
+
+// Split returns an iterator over the CIDRs of all subnets of the given size within the subnet, in address order.
+// The CIDRs are computed as they are iterated and the tree is left unchanged, so large splits take no memory up
+// front. Use AddReservation to reserve any of them.
+func (s *Subnet) Split(newSize int) (iter.Seq[string], error) {
+	if newSize < s.Size() || newSize > 32 {
+		return nil, &SubnetError{CIDR: s.CIDR(), Err: ErrInvalidSize}
+	}
+
+	return func(yield func(string) bool) {
+		step := inetSubnetAddresses(newSize)
+		for network := s.network(); network <= s.broadcast(); network += step {
+			if !yield(inetNToA(network) + "/" + strconv.Itoa(newSize)) {
+				return
+			}
+		}
+	}, nil
+}
+
+// SplitN splits the subnet into the smallest power of two number of equally sized subnets that is at least n
+func (s *Subnet) SplitN(n int) (iter.Seq[string], error) {
+	if n < 1 {
+		return nil, &SubnetError{CIDR: s.CIDR(), Err: ErrInvalidSize}
+	}
+
+	bits := 0
+	for 1<<bits < n {
+		bits++
+	}
+	return s.Split(s.Size() + bits)
+}
+
+// Supernet returns the subnet of the given size containing the subnet, found among its parents in the tree
+func (s *Subnet) Supernet(size int) (*Subnet, error) {
+	cidr, err := s.SupernetCIDR(size)
+	if err != nil {
+		return nil, err
+	}
+
+	for n := s; n != nil; n = n.parent() {
+		if n.Size() == size {
+			return n, nil
+		}
+	}
+	return nil, &NotFoundError{Root: s.root().CIDR(), CIDR: cidr}
+}
+
+// SupernetCIDR returns the CIDR of the prefix of the given size containing the subnet, which may be larger than
+// the root of the tree
+func (s *Subnet) SupernetCIDR(size int) (string, error) {
+	if size < 0 || size > s.Size() {
+		return "", &SubnetError{CIDR: s.CIDR(), Err: ErrInvalidSize}
+	}
+
+	network := s.network() &^ (inetSubnetAddresses(size) - 1)
+	return fmt.Sprintf("%s/%d", inetNToA(network), size), nil
+}
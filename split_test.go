@@ -0,0 +1,110 @@
+package subnetcalc
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Split(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	t.Run("Ok", func(t *testing.T) {
+		children, err := s.Split(18)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.0/18", "10.0.64.0/18", "10.0.128.0/18", "10.0.192.0/18"}, slices.Collect(children))
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		children, err := s.Split(16)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"10.0.0.0/16"}, slices.Collect(children))
+	})
+
+	t.Run("Large", func(t *testing.T) {
+		nodes := len(s.Collect())
+		var events []Event
+		s.AddObserver(ObserverFunc(func(e Event) { events = append(events, e) }))
+
+		children, err := s.Split(32)
+		assert.NoError(t, err)
+
+		count := 0
+		var last string
+		for cidr := range children {
+			count++
+			last = cidr
+		}
+		assert.Equal(t, 65536, count)
+		assert.Equal(t, "10.0.255.255/32", last)
+		assert.Equal(t, nodes, len(s.Collect()), "splitting should not divide the tree")
+		assert.Empty(t, events)
+	})
+
+	t.Run("Stop early", func(t *testing.T) {
+		children, err := s.Split(24)
+		assert.NoError(t, err)
+		for cidr := range children {
+			assert.Equal(t, "10.0.0.0/24", cidr)
+			break
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := s.Split(15)
+		assert.ErrorIs(t, err, ErrInvalidSize)
+		_, err = s.Split(33)
+		assert.ErrorIs(t, err, ErrInvalidSize)
+	})
+}
+
+func Test_SplitN(t *testing.T) {
+	s, err := Parse("10.0.0.0/24")
+	assert.NoError(t, err, "parse should return no error")
+
+	children, err := s.SplitN(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/26", "10.0.0.192/26"}, slices.Collect(children))
+
+	children, err = s.SplitN(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/24"}, slices.Collect(children))
+
+	_, err = s.SplitN(0)
+	assert.ErrorIs(t, err, ErrInvalidSize)
+	_, err = s.SplitN(512)
+	assert.ErrorIs(t, err, ErrInvalidSize)
+}
+
+func Test_Supernet(t *testing.T) {
+	s, err := Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	sn, err := s.AddReservation("10.0.37.0/24", "app")
+	assert.NoError(t, err)
+
+	super, err := sn.Supernet(20)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.32.0/20", super.CIDR())
+
+	super, err = sn.Supernet(24)
+	assert.NoError(t, err)
+	assert.Equal(t, sn, super)
+
+	_, err = sn.Supernet(8)
+	assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+	assert.EqualError(t, err, "could not find suitable subnet: 10.0.0.0/8 is not within 10.0.0.0/16")
+
+	cidr, err := sn.SupernetCIDR(12)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.0/12", cidr)
+
+	cidr, err = sn.SupernetCIDR(21)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.32.0/21", cidr)
+
+	_, err = sn.SupernetCIDR(25)
+	assert.ErrorIs(t, err, ErrInvalidSize)
+}
@@ -0,0 +1,119 @@
+// Package sqlitestore implements a subnetcalc.Store keeping trees in a SQLite database
+package sqlitestore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/kschjeld/subnetcalc"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const schema = `CREATE TABLE IF NOT EXISTS subnetcalc (
+	name    TEXT PRIMARY KEY,
+	version INTEGER NOT NULL,
+	tree    TEXT NOT NULL
+)`
+
+// Store keeps a named tree in a SQLite database, which handles locking between processes
+type Store struct {
+	db   *sql.DB
+	name string
+}
+
+// Open opens the SQLite database file at path, creating it if needed, and returns a Store for the tree with the
+// given name
+func Open(path, name string) (*Store, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := New(db, name)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// New returns a Store for the tree with the given name in an already opened database, creating the table
+// if needed
+func New(db *sql.DB, name string) (*Store, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+	return &Store{db: db, name: name}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Load returns the stored tree and its version
+func (s *Store) Load() (*subnetcalc.Subnet, uint64, error) {
+	var data string
+	var version uint64
+
+	err := s.db.QueryRow(`SELECT version, tree FROM subnetcalc WHERE name = ?`, s.name).Scan(&version, &data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, subnetcalc.ErrEmptyStore
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	root, err := subnetcalc.ParseJSON([]byte(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	return root, version, nil
+}
+
+// Save stores the tree unconditionally
+func (s *Store) Save(root *subnetcalc.Subnet) (uint64, error) {
+	data, err := json.Marshal(root)
+	if err != nil {
+		return 0, err
+	}
+
+	var version uint64
+	err = s.db.QueryRow(`INSERT INTO subnetcalc (name, version, tree) VALUES (?, 1, ?)
+		ON CONFLICT (name) DO UPDATE SET version = version + 1, tree = excluded.tree
+		RETURNING version`, s.name, string(data)).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// CompareAndSwap stores the tree if the stored version is unchanged
+func (s *Store) CompareAndSwap(version uint64, root *subnetcalc.Subnet) (uint64, error) {
+	data, err := json.Marshal(root)
+	if err != nil {
+		return 0, err
+	}
+
+	var res sql.Result
+	if version == 0 {
+		res, err = s.db.Exec(`INSERT INTO subnetcalc (name, version, tree) VALUES (?, 1, ?) ON CONFLICT (name) DO NOTHING`,
+			s.name, string(data))
+	} else {
+		res, err = s.db.Exec(`UPDATE subnetcalc SET version = version + 1, tree = ? WHERE name = ? AND version = ?`,
+			string(data), s.name, version)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, subnetcalc.ErrVersionConflict
+	}
+	return version + 1, nil
+}
@@ -0,0 +1,51 @@
+package sqlitestore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/kschjeld/subnetcalc"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Store(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "subnets.db"), "prod")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	_, _, err = store.Load()
+	assert.ErrorIs(t, err, subnetcalc.ErrEmptyStore)
+
+	s, err := subnetcalc.Parse("10.0.0.0/16")
+	assert.NoError(t, err, "parse should return no error")
+
+	_, err = store.CompareAndSwap(1, s)
+	assert.ErrorIs(t, err, subnetcalc.ErrVersionConflict)
+
+	version, err := store.CompareAndSwap(0, s)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+
+	err = subnetcalc.Update(store, func(root *subnetcalc.Subnet) error {
+		_, err := root.FindFreeAndReserve(24, "web")
+		return err
+	})
+	assert.NoError(t, err)
+
+	loaded, version, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), version)
+	assert.Equal(t, "10.0.0.0/24", loaded.FindByName("web")[0].CIDR())
+
+	_, err = store.CompareAndSwap(1, loaded)
+	assert.ErrorIs(t, err, subnetcalc.ErrVersionConflict)
+
+	version, err = store.Save(loaded)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), version)
+
+	other, err := New(store.db, "dev")
+	assert.NoError(t, err)
+	_, _, err = other.Load()
+	assert.ErrorIs(t, err, subnetcalc.ErrEmptyStore, "trees are stored by name")
+}
@@ -0,0 +1,164 @@
+package subnetcalc
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+var ErrVersionConflict = errors.New("stored tree was changed concurrently")
+var ErrEmptyStore = errors.New("no tree is stored")
+
+// updateRetries is the number of attempts Update makes before giving up on version conflicts
+const updateRetries = 10
+
+// Store persists a subnet tree along with a version, allowing several processes to share an address pool
+// using optimistic concurrency
+type Store interface {
+	// Load returns the stored tree and its version, or ErrEmptyStore if nothing is stored yet
+	Load() (*Subnet, uint64, error)
+
+	// Save stores the tree unconditionally and returns the new version
+	Save(root *Subnet) (uint64, error)
+
+	// CompareAndSwap stores the tree only if the stored version still is version, where version 0 means nothing
+	// is stored yet, and returns the new version. ErrVersionConflict is returned if the version has changed.
+	CompareAndSwap(version uint64, root *Subnet) (uint64, error)
+}
+
+// Update loads the tree from the store, applies fn to it and stores the result, starting over with a fresh copy
+// of the tree if it was changed concurrently. An error from fn aborts the update without storing anything.
+func Update(store Store, fn func(root *Subnet) error) error {
+	for i := 0; i < updateRetries; i++ {
+		root, version, err := store.Load()
+		if err != nil {
+			return err
+		}
+
+		if err = fn(root); err != nil {
+			return err
+		}
+
+		_, err = store.CompareAndSwap(version, root)
+		if !errors.Is(err, ErrVersionConflict) {
+			return err
+		}
+	}
+
+	return ErrVersionConflict
+}
+
+// FileStore is a Store keeping the tree in a local JSON file. Access is serialized between processes by
+// locking a separate lock file next to it.
+type FileStore struct {
+	path string
+}
+
+type fileStoreData struct {
+	Version uint64          `json:"version"`
+	Tree    json.RawMessage `json:"tree"`
+}
+
+// NewFileStore returns a FileStore keeping the tree in the file at path
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load returns the stored tree and its version
+func (f *FileStore) Load() (*Subnet, uint64, error) {
+	unlock, err := lockFile(f.path+".lock", false)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer unlock()
+
+	data, err := f.read()
+	if err != nil {
+		return nil, 0, err
+	}
+	if data.Version == 0 {
+		return nil, 0, ErrEmptyStore
+	}
+
+	root, err := ParseJSON(data.Tree)
+	if err != nil {
+		return nil, 0, err
+	}
+	return root, data.Version, nil
+}
+
+// Save stores the tree unconditionally
+func (f *FileStore) Save(root *Subnet) (uint64, error) {
+	return f.store(root, func(uint64) bool { return true })
+}
+
+// CompareAndSwap stores the tree if the stored version is unchanged
+func (f *FileStore) CompareAndSwap(version uint64, root *Subnet) (uint64, error) {
+	return f.store(root, func(current uint64) bool { return current == version })
+}
+
+func (f *FileStore) store(root *Subnet, check func(current uint64) bool) (uint64, error) {
+	tree, err := json.Marshal(root)
+	if err != nil {
+		return 0, err
+	}
+
+	unlock, err := lockFile(f.path+".lock", true)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	data, err := f.read()
+	if err != nil {
+		return 0, err
+	}
+	if !check(data.Version) {
+		return 0, ErrVersionConflict
+	}
+
+	data.Version++
+	data.Tree = tree
+	content, err := json.Marshal(data)
+	if err != nil {
+		return 0, err
+	}
+
+	// Replace the file atomically, so readers never see a partial write
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err = tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err = os.Rename(tmp.Name(), f.path); err != nil {
+		return 0, err
+	}
+
+	return data.Version, nil
+}
+
+// read returns the current content of the file, with version 0 if it does not exist
+func (f *FileStore) read() (*fileStoreData, error) {
+	content, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &fileStoreData{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var data fileStoreData
+	if err = json.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
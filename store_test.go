@@ -0,0 +1,120 @@
+package subnetcalc
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FileStore(t *testing.T) {
+	t.Run("Save and load", func(t *testing.T) {
+		store := NewFileStore(filepath.Join(t.TempDir(), "tree.json"))
+
+		_, _, err := store.Load()
+		assert.ErrorIs(t, err, ErrEmptyStore)
+
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		_, err = s.AddReservation("10.0.0.0/24", "web")
+		assert.NoError(t, err)
+
+		version, err := store.Save(s)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), version)
+
+		loaded, version, err := store.Load()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), version)
+		assert.Equal(t, "web", loaded.FindByName("web")[0].Reservation())
+	})
+
+	t.Run("Compare and swap", func(t *testing.T) {
+		store := NewFileStore(filepath.Join(t.TempDir(), "tree.json"))
+
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		_, err = store.CompareAndSwap(1, s)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+
+		version, err := store.CompareAndSwap(0, s)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), version)
+
+		_, err = store.CompareAndSwap(0, s)
+		assert.ErrorIs(t, err, ErrVersionConflict)
+
+		version, err = store.CompareAndSwap(1, s)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), version)
+	})
+}
+
+func Test_Update(t *testing.T) {
+	t.Run("Concurrent allocations", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tree.json")
+
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		_, err = NewFileStore(path).Save(s)
+		assert.NoError(t, err)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 4)
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- Update(NewFileStore(path), func(root *Subnet) error {
+					_, err := root.FindFreeAndReserve(24, fmt.Sprintf("worker-%d", i))
+					return err
+				})
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				assert.ErrorIs(t, err, ErrVersionConflict, "only conflicts are expected under contention")
+			}
+		}
+
+		loaded, _, err := NewFileStore(path).Load()
+		assert.NoError(t, err)
+		reserved := loaded.Collect(SelectReserved())
+		assert.NotEmpty(t, reserved)
+		for i, r := range reserved {
+			assert.Equal(t, fmt.Sprintf("10.0.%d.0/24", i), r.CIDR(), "no allocation is lost or duplicated")
+		}
+	})
+
+	t.Run("Error aborts", func(t *testing.T) {
+		store := NewFileStore(filepath.Join(t.TempDir(), "tree.json"))
+
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		_, err = store.Save(s)
+		assert.NoError(t, err)
+
+		err = Update(store, func(root *Subnet) error {
+			_, err := root.FindFreeAndReserve(8, "too-large")
+			return err
+		})
+		assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+
+		_, version, err := store.Load()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), version)
+	})
+
+	t.Run("Empty store", func(t *testing.T) {
+		err := Update(NewFileStore(filepath.Join(t.TempDir(), "tree.json")), func(root *Subnet) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrEmptyStore)
+	})
+}
@@ -3,19 +3,38 @@ package subnetcalc
 import (
 	"errors"
 	"net"
+	"strconv"
+	"unsafe"
 )
 
+// CIDR is an IPv4 address range packed as network address and prefix length
 type CIDR struct {
-	net net.IPNet
+	addr uint32
+	bits uint8
 }
 
+// Subnet is a node in the tree of subnets. Nodes are kept small as a fully divided tree holds one per address:
+// the range is packed into the first word along with the flags, children are allocated in pairs, and the names
+// of the few reserved subnets are kept by the root along with the other state shared by the tree.
 type Subnet struct {
-	cidr      CIDR
-	parent    *Subnet
-	low, high *Subnet
+	addr     uint32 // Network address
+	bits     uint8  // Prefix length
+	pool     bool
+	reserved bool
+	child    bool // Set on all subnets but the root, telling what up points to
 
-	reservation     string
 	subReservations int
+	up              unsafe.Pointer // Parent subnet, or the tree state of the root
+	children        *[2]Subnet
+}
+
+// tree holds state shared by all subnets in a tree, and is only set on the root
+type tree struct {
+	policies     []Policy
+	reservations map[*Subnet]string
+	names        map[string][]*Subnet
+	reserved     PrefixSet[*Subnet]
+	observers    []Observer
 }
 
 var ErrCouldNotParse = errors.New("could not parse subnet specification")
@@ -52,10 +71,8 @@ func Parse(s string) (*Subnet, error) {
 		return nil, err
 	}
 	subnet := &Subnet{
-		cidr:   *c,
-		parent: nil,
-		low:    nil,
-		high:   nil,
+		addr: c.addr,
+		bits: c.bits,
 	}
 
 	subnet.initialize()
@@ -66,30 +83,32 @@ func Parse(s string) (*Subnet, error) {
 // CIDR returns CIDR range of subnet as a string
 func (s *Subnet) CIDR() string {
 	if s != nil {
-		return s.cidr.net.String()
+		return inetNToA(s.network()) + "/" + strconv.Itoa(s.Size())
 	}
 	return ""
 }
 
 // Size returns size of subnet as a string
 func (s *Subnet) Size() int {
-	size, _ := s.cidr.net.Mask.Size()
-	return size
+	return int(s.bits)
 }
 
 // FirstIP returns the first usable IP in the subnet as a string
 func (s *Subnet) FirstIP() string {
-	return inetNToA(inetBToN(s.cidr.net.IP) + 1)
+	return inetNToA(s.network() + 1)
 }
 
 // LastIP returns the last usable IP in the subnet as a string
 func (s *Subnet) LastIP() string {
-	return inetNToA(inetSubnetLastAddress(inetBToN(s.cidr.net.IP), s.Size()) - 1)
+	return inetNToA(s.broadcast() - 1)
 }
 
 // Reservation will return the current reservation name of the subnet, if set
 func (s *Subnet) Reservation() string {
-	return s.reservation
+	if !s.reserved {
+		return ""
+	}
+	return s.state().reservations[s]
 }
 
 // HasChildReservations is true if the subnet as any reserved child subnets
@@ -104,93 +123,59 @@ func (s *Subnet) AddReservation(subnetCidr string, name string) (*Subnet, error)
 		return nil, err
 	}
 
-	if s.cidr.net.String() == cidr.net.String() {
-
-		if s.reservation != "" {
-			if s.reservation == name {
-				return s, nil
-			} else {
-				return s, ErrAlreadyReserved
-			}
-		}
-
-		s.reservation = name
-		if s.parent != nil {
-			s.parent.addSubReservation()
-		}
-		return s, nil
+	// Reservations are indexed, so an existing one is checked without walking the tree
+	if n := s.state().reserved.covering(cidr.addr, int(cidr.bits)); n != nil && n.bits == int(cidr.bits) && n.value.within(s) {
+		return n.value, n.value.Reserve(name)
 	}
 
-	if s.cidr.net.Contains(cidr.net.IP) {
-		_ = s.divide()
-		if s.low != nil && s.low.cidr.net.Contains(cidr.net.IP) {
-			return s.low.AddReservation(subnetCidr, name)
-		} else if s.high != nil {
-			return s.high.AddReservation(subnetCidr, name)
-		}
+	sn := s.find(cidr)
+	if sn == nil {
+		return nil, &NotFoundError{Root: s.CIDR(), CIDR: subnetCidr}
 	}
 
-	return nil, ErrDidNotFindSubnet
+	return sn, sn.Reserve(name)
 }
 
 // Collect will do a left first search of the subnet tree hierarchy and apply the specified filter functions
 func (s *Subnet) Collect(filterFunc ...func(s *Subnet) bool) []*Subnet {
 	var res []*Subnet
 
-	if s == nil {
-		return res
-	}
-
-	match := true
-	for _, fn := range filterFunc {
-		match = fn(s)
-		if !match {
-			break
+	s.Walk(func(sn *Subnet) (bool, bool) {
+		for _, fn := range filterFunc {
+			if !fn(sn) {
+				return true, false
+			}
 		}
-	}
-
-	if match {
-		res = append(res, s)
-	}
-
-	res = append(res, s.low.Collect(filterFunc...)...)
-	res = append(res, s.high.Collect(filterFunc...)...)
+		res = append(res, sn)
+		return true, false
+	})
 
 	return res
 }
 
-// FindFree searches for an available subnet of the given size
+// FindFree searches for an available subnet of the given size. Reserved subnets are not searched, except when
+// called on a pool, which is searched for available subnets within it.
 func (s *Subnet) FindFree(requiredSize int) (*Subnet, error) {
-	if s.Size() == requiredSize && s.reservation == "" && s.subReservations == 0 {
-		return s, nil
-	}
+	return s.findFreeMatching(requiredSize, nil)
+}
 
+// findFreeMatching searches like FindFree, skipping available subnets not accepted by the allowed function
+func (s *Subnet) findFreeMatching(requiredSize int, allowed func(s *Subnet) bool) (*Subnet, error) {
 	var found *Subnet
-	var err error
-	if s.reservation == "" && s.Size() < requiredSize {
-		s.divide()
-
-		if s.low != nil {
-			found, err = s.low.FindFree(requiredSize)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		if found == nil && s.high != nil {
-			found, err = s.high.FindFree(requiredSize)
-			if err != nil {
-				return nil, err
-			}
+	if s.pool && s.Size() < requiredSize {
+		_ = s.divide()
+		if found = s.low().findFree(requiredSize, allowed); found == nil {
+			found = s.high().findFree(requiredSize, allowed)
 		}
+	} else {
+		found = s.findFree(requiredSize, allowed)
 	}
 
-	// Top level detects nothing found, and returns error instead of nil
-	if found == nil && s.parent == nil {
-		return nil, ErrDidNotFindSubnet
+	if found == nil {
+		return nil, &NotFoundError{Root: s.CIDR(), Size: requiredSize}
 	}
 
-	return found, err
+	return found, nil
 }
 
 // Reserve adds a reservation on the subnet if is is free. Readding with same reservation name will not fail.
@@ -199,26 +184,33 @@ func (s *Subnet) Reserve(name string) error {
 		return nil
 	}
 
-	if s.reservation != "" {
-		if s.reservation == name {
+	if s.reserved {
+		if s.Reservation() == name {
 			return nil
 		}
-		return ErrAlreadyReserved
+		return &ConflictError{CIDR: s.CIDR(), Name: name, Conflict: s, Reservation: s.Reservation()}
 	}
 
-	s.reservation = name
-	if s.parent != nil {
-		s.parent.addSubReservation()
+	if err := s.checkPolicies(name); err != nil {
+		return err
 	}
 
+	s.setReservation(name)
+
 	return nil
 }
 
-// FindFreeAndReserve combines FindFree and Reserve into one operation
+// FindFreeAndReserve combines FindFree and Reserve into one operation. Available subnets where the reservation
+// would violate the policies of the tree are skipped.
 func (s *Subnet) FindFreeAndReserve(size int, name string) (*Subnet, error) {
-	sn, err := s.FindFree(size)
+	sn, err := s.findFreeMatching(size, func(sn *Subnet) bool {
+		return sn.checkPolicies(name) == nil
+	})
 	if err != nil {
-		return nil, err
+		// No subnet satisfies the policies, reserving the first available one reports why
+		if sn, err = s.FindFree(size); err != nil {
+			return nil, err
+		}
 	}
 
 	if err = sn.Reserve(name); err != nil {
@@ -230,18 +222,41 @@ func (s *Subnet) FindFreeAndReserve(size int, name string) (*Subnet, error) {
 
 // UnReserve removes a reservation
 func (s *Subnet) UnReserve() error {
-	if s.reservation == "" {
-		return ErrNotReserved
+	if !s.reserved {
+		return &SubnetError{CIDR: s.CIDR(), Err: ErrNotReserved}
 	}
-
-	s.reservation = ""
-	if s.parent != nil {
-		s.parent.removeSubReservation()
+	if s.pool && s.subReservations > 0 {
+		return &SubnetError{CIDR: s.CIDR(), Err: ErrPoolInUse}
 	}
 
+	s.clearReservation()
+
 	return nil
 }
 
+func (s *Subnet) findFree(requiredSize int, allowed func(s *Subnet) bool) *Subnet {
+	if s == nil {
+		return nil
+	}
+
+	if s.Size() == requiredSize && !s.reserved && s.subReservations == 0 {
+		if allowed != nil && !allowed(s) {
+			return nil
+		}
+		return s
+	}
+
+	if s.reserved || s.Size() >= requiredSize {
+		return nil
+	}
+
+	_ = s.divide()
+	if found := s.low().findFree(requiredSize, allowed); found != nil {
+		return found
+	}
+	return s.high().findFree(requiredSize, allowed)
+}
+
 func (s *Subnet) initialize() {
 	if s == nil {
 		return
@@ -252,15 +267,15 @@ func (s *Subnet) initialize() {
 	}
 
 	s.divide()
-	s.low.initialize()
-	s.high.initialize()
+	s.low().initialize()
+	s.high().initialize()
 }
 
 func (s *Subnet) divide() error {
 	if s == nil {
 		return nil
 	}
-	if s.low != nil && s.high != nil {
+	if s.children != nil {
 		return nil
 	}
 
@@ -269,72 +284,212 @@ func (s *Subnet) divide() error {
 		return err
 	}
 
-	if s.low == nil {
-		s.low = &Subnet{
-			cidr:   low,
-			parent: s,
-			low:    nil,
-			high:   nil,
-		}
+	// Allocate both children at once, halving the allocations of building a tree
+	s.children = &[2]Subnet{
+		{addr: low.addr, bits: low.bits, child: true, up: unsafe.Pointer(s)},
+		{addr: high.addr, bits: high.bits, child: true, up: unsafe.Pointer(s)},
+	}
+
+	return nil
+}
+
+func (s *Subnet) lowAndHigh() (CIDR, CIDR, error) {
+	if s.children != nil {
+		low, high := &s.children[0], &s.children[1]
+		return CIDR{addr: low.addr, bits: low.bits}, CIDR{addr: high.addr, bits: high.bits}, nil
+	}
+
+	if s.bits >= 32 {
+		return CIDR{}, CIDR{}, &SubnetError{CIDR: s.CIDR(), Err: ErrNotDividable}
 	}
 
-	if s.high == nil {
-		s.high = &Subnet{
-			cidr:   high,
-			parent: s,
-			low:    nil,
-			high:   nil,
+	low := CIDR{addr: s.addr, bits: s.bits + 1}
+	high := CIDR{addr: s.addr | 1<<(31-s.bits), bits: s.bits + 1}
+	return low, high, nil
+}
+
+// find returns the subnet matching the CIDR, dividing subnets on the way as needed, or nil if it is not
+// within the subnet
+func (s *Subnet) find(cidr *CIDR) *Subnet {
+	target := cidr.subnet()
+
+	node := s
+	for target.within(node) {
+		if node.Size() == target.Size() {
+			return node
+		}
+
+		_ = node.divide()
+		if target.within(node.low()) {
+			node = node.low()
+		} else {
+			node = node.high()
 		}
 	}
+
 	return nil
 }
 
-func (s *Subnet) lowAndHigh() (CIDR, CIDR, error) {
-	if s.low != nil && s.high != nil {
-		return s.low.cidr, s.high.cidr, nil
+// findCIDR parses the CIDR and returns the matching subnet
+func (s *Subnet) findCIDR(subnetCidr string) (*Subnet, error) {
+	cidr, err := toCIDR(subnetCidr)
+	if err != nil {
+		return nil, err
 	}
 
-	size := s.Size()
-	if size >= 32 {
-		return CIDR{}, CIDR{}, ErrNotDividable
+	sn := s.find(cidr)
+	if sn == nil {
+		return nil, &NotFoundError{Root: s.CIDR(), CIDR: subnetCidr}
 	}
+	return sn, nil
+}
 
-	low := CIDR{
-		net: net.IPNet{
-			IP:   s.cidr.net.IP,
-			Mask: inetNToB(inetSubnetNetmask(size + 1)),
-		},
+// parent returns the subnet the subnet was divided from, or nil for the root
+func (s *Subnet) parent() *Subnet {
+	if !s.child {
+		return nil
 	}
+	return (*Subnet)(s.up)
+}
 
-	high := CIDR{
-		net: net.IPNet{
-			IP:   inetNToB(inetSubnetLastAddress(inetBToN(s.cidr.net.IP), size+1) + 1),
-			Mask: inetNToB(inetSubnetNetmask(size + 1)),
-		},
+// low returns the lower half of the subnet, or nil if it is not divided
+func (s *Subnet) low() *Subnet {
+	if s.children == nil {
+		return nil
 	}
-	return low, high, nil
+	return &s.children[0]
+}
+
+// high returns the upper half of the subnet, or nil if it is not divided
+func (s *Subnet) high() *Subnet {
+	if s.children == nil {
+		return nil
+	}
+	return &s.children[1]
+}
+
+// root returns the top level subnet of the tree
+func (s *Subnet) root() *Subnet {
+	for s.child {
+		s = (*Subnet)(s.up)
+	}
+	return s
+}
+
+// state returns the shared state of the tree
+func (s *Subnet) state() *tree {
+	r := s.root()
+	if r.up == nil {
+		r.up = unsafe.Pointer(&tree{})
+	}
+	return (*tree)(r.up)
+}
+
+// network returns the network address of the subnet as an integer
+func (s *Subnet) network() int {
+	return int(s.addr)
+}
+
+// broadcast returns the last address of the subnet as an integer
+func (s *Subnet) broadcast() int {
+	return inetSubnetLastAddress(s.network(), s.Size())
+}
+
+// overlaps is true if the address ranges of the two subnets intersect
+func (s *Subnet) overlaps(o *Subnet) bool {
+	return s.network() <= o.broadcast() && o.network() <= s.broadcast()
+}
+
+// within is true if the subnet is contained in the other subnet
+func (s *Subnet) within(o *Subnet) bool {
+	return o.network() <= s.network() && s.broadcast() <= o.broadcast()
+}
+
+// contains is true if the address is part of the subnet
+func (s *Subnet) contains(ip int) bool {
+	return s.network() <= ip && ip <= s.broadcast()
+}
+
+// depth returns the number of parents of the subnet
+func (s *Subnet) depth() int {
+	depth := 0
+	for p := s.parent(); p != nil; p = p.parent() {
+		depth++
+	}
+	return depth
+}
+
+// setReservation reserves the subnet, updating parents, the name index and the reserved prefix index
+func (s *Subnet) setReservation(name string) {
+	st := s.state()
+	if st.reservations == nil {
+		st.reservations = map[*Subnet]string{}
+	}
+	st.reservations[s] = name
+	s.reserved = true
+	if p := s.parent(); p != nil {
+		p.addSubReservation()
+	}
+	st.addName(s, name)
+	st.reserved.insert(uint32(s.network()), s.Size(), s)
+	s.notify(Event{Type: EventReserved, Name: name, Pool: s.pool})
+}
+
+// clearReservation removes the reservation of the subnet, updating parents, the name index and the reserved
+// prefix index
+func (s *Subnet) clearReservation() {
+	st := s.state()
+	name, pool := st.reservations[s], s.pool
+	st.removeName(s, name)
+	st.reserved.delete(uint32(s.network()), s.Size())
+	delete(st.reservations, s)
+	s.reserved = false
+	s.pool = false
+	if p := s.parent(); p != nil {
+		p.removeSubReservation()
+	}
+	s.notify(Event{Type: EventUnreserved, OldName: name, OldPool: pool})
 }
 
 func (s *Subnet) addSubReservation() {
 	s.subReservations = s.subReservations + 1
-	if s.parent != nil {
-		s.parent.addSubReservation()
+	if p := s.parent(); p != nil {
+		p.addSubReservation()
 	}
 }
 
 func (s *Subnet) removeSubReservation() {
 	s.subReservations = s.subReservations - 1
-	if s.parent != nil {
-		s.parent.removeSubReservation()
+	if p := s.parent(); p != nil {
+		p.removeSubReservation()
 	}
 }
 
 func toCIDR(s string) (*CIDR, error) {
 	_, snet, err := net.ParseCIDR(s)
 	if err != nil {
-		return nil, ErrCouldNotParse
+		return nil, &ParseError{Input: s, Err: err}
+	}
+	ip := snet.IP.To4()
+	size, bits := snet.Mask.Size()
+	if ip == nil || bits != 32 {
+		return nil, &ParseError{Input: s, Err: &net.ParseError{Type: "IPv4 CIDR address", Text: s}}
 	}
 	return &CIDR{
-		net: *snet,
+		addr: uint32(inetBToN(ip)),
+		bits: uint8(size),
 	}, nil
 }
+
+// subnet returns a detached subnet for the range, for comparing with subnets in trees
+func (c *CIDR) subnet() *Subnet {
+	return &Subnet{addr: c.addr, bits: c.bits}
+}
+
+func toIP(s string) (int, error) {
+	ip := net.ParseIP(s).To4()
+	if ip == nil {
+		return 0, &ParseError{Input: s, Err: &net.ParseError{Type: "IPv4 address", Text: s}}
+	}
+	return inetBToN(ip), nil
+}
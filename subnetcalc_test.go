@@ -64,7 +64,7 @@ func Test_AddReservationFail(t *testing.T) {
 		assert.NoError(t, err)
 
 		_, err = s.AddReservation("10.0.0.0/24", "test-2")
-		assert.ErrorIs(t, ErrAlreadyReserved, err)
+		assert.ErrorIs(t, err, ErrAlreadyReserved)
 	})
 }
 
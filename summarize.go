@@ -0,0 +1,149 @@
+package subnetcalc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Summary is a prefix covering one or more summarized subnets
+type Summary struct {
+	Subnet         *Subnet   // Covering subnet
+	Covers         []*Subnet // Summarized subnets within it
+	Extra          []*Subnet // Largest blocks within the covering subnet not covered by any summarized subnet
+	ExtraAddresses int       // Number of addresses in the extra blocks
+}
+
+// String returns the covering CIDR followed by the extra blocks it includes, if any
+func (s Summary) String() string {
+	if len(s.Extra) == 0 {
+		return s.Subnet.CIDR()
+	}
+
+	extra := make([]string, 0, len(s.Extra))
+	for _, e := range s.Extra {
+		extra = append(extra, e.CIDR())
+	}
+	return fmt.Sprintf("%s (extra %s)", s.Subnet.CIDR(), strings.Join(extra, ", "))
+}
+
+// Summarize returns the fewest prefixes covering the subnets, in address order. Sibling subnets are always
+// aggregated into their parent, and further aggregation including up to maxExtra addresses outside the
+// subnets in total is done greedily, preferring merges adding the least extra space per prefix saved. All
+// subnets must be in the same tree.
+func Summarize(nodes []*Subnet, maxExtra int) ([]Summary, error) {
+	var list []*Subnet
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		if len(list) > 0 && n.root() != list[0].root() {
+			return nil, fmt.Errorf("%w: %s and %s", ErrRootMismatch, list[0].root().CIDR(), n.root().CIDR())
+		}
+		list = append(list, n)
+	}
+
+	// Sort by network with larger subnets first, so subnets within others can be dropped in one pass
+	slices.SortFunc(list, func(a, b *Subnet) int {
+		if a.network() != b.network() {
+			return a.network() - b.network()
+		}
+		return a.Size() - b.Size()
+	})
+	var covered []*Subnet
+	for _, n := range list {
+		if len(covered) == 0 || !n.within(covered[len(covered)-1]) {
+			covered = append(covered, n)
+		}
+	}
+
+	summaries := mergeSiblings(covered)
+	budget := maxExtra
+	for {
+		var best *Subnet
+		bestStart, bestEnd, bestCost := 0, 0, 0
+		for i := 0; i+1 < len(summaries); i++ {
+			c := commonAncestor(summaries[i], summaries[i+1])
+
+			first, end, addresses := i, i+2, 0
+			for first > 0 && summaries[first-1].within(c) {
+				first--
+			}
+			for end < len(summaries) && summaries[end].within(c) {
+				end++
+			}
+			for _, sn := range summaries[first:end] {
+				addresses += inetSubnetAddresses(sn.Size())
+			}
+			cost := inetSubnetAddresses(c.Size()) - addresses
+
+			// Compare extra addresses per prefix saved
+			if cost <= budget && (best == nil || cost*(bestEnd-bestStart-1) < bestCost*(end-first-1)) {
+				best, bestStart, bestEnd, bestCost = c, first, end, cost
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		budget -= bestCost
+		summaries = mergeSiblings(slices.Replace(summaries, bestStart, bestEnd, best))
+	}
+
+	res := make([]Summary, 0, len(summaries))
+	for _, sn := range summaries {
+		sum := Summary{Subnet: sn}
+		for _, n := range covered {
+			if n.within(sn) {
+				sum.Covers = append(sum.Covers, n)
+			}
+		}
+		sum.Extra = extraBlocks(sn, sum.Covers)
+		for _, e := range sum.Extra {
+			sum.ExtraAddresses += inetSubnetAddresses(e.Size())
+		}
+		res = append(res, sum)
+	}
+
+	return res, nil
+}
+
+// mergeSiblings replaces sibling subnets in a sorted list of disjoint subnets with their parent, repeatedly
+func mergeSiblings(list []*Subnet) []*Subnet {
+	var res []*Subnet
+	for _, n := range list {
+		res = append(res, n)
+		for len(res) > 1 {
+			a, b := res[len(res)-2], res[len(res)-1]
+			if a.parent() == nil || a.parent() != b.parent() {
+				break
+			}
+			res = append(res[:len(res)-2], a.parent())
+		}
+	}
+	return res
+}
+
+// commonAncestor returns the smallest subnet containing both subnets of the same tree
+func commonAncestor(a, b *Subnet) *Subnet {
+	c := a
+	for !b.within(c) {
+		c = c.parent()
+	}
+	return c
+}
+
+// extraBlocks returns the largest blocks within the subnet not covered by any of the covers
+func extraBlocks(s *Subnet, covers []*Subnet) []*Subnet {
+	overlapped := false
+	for _, c := range covers {
+		if s.within(c) {
+			return nil
+		}
+		overlapped = overlapped || c.overlaps(s)
+	}
+	if !overlapped {
+		return []*Subnet{s}
+	}
+	return append(extraBlocks(s.low(), covers), extraBlocks(s.high(), covers)...)
+}
@@ -0,0 +1,110 @@
+package subnetcalc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Summarize(t *testing.T) {
+	t.Run("Siblings", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		var nodes []*Subnet
+		for _, cidr := range []string{"10.0.1.0/24", "10.0.0.0/24", "10.0.2.0/24", "10.0.3.0/25", "10.0.3.128/25", "10.0.8.0/24"} {
+			sn, err := s.AddReservation(cidr, cidr)
+			assert.NoError(t, err)
+			nodes = append(nodes, sn)
+		}
+
+		res, err := Summarize(nodes, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "[10.0.0.0/22 10.0.8.0/24]", fmt.Sprint(res))
+		assert.Equal(t, []*Subnet{nodes[1], nodes[0], nodes[2], nodes[3], nodes[4]}, res[0].Covers)
+		assert.Empty(t, res[0].Extra)
+		assert.Equal(t, 0, res[0].ExtraAddresses)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		low, err := s.AddReservation("10.0.0.0/23", "low")
+		assert.NoError(t, err)
+		high, err := s.AddReservation("10.0.2.0/23", "high")
+		assert.NoError(t, err)
+		pool, err := s.findCIDR("10.0.1.0/24")
+		assert.NoError(t, err)
+
+		res, err := Summarize([]*Subnet{low, high, pool, nil}, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "[10.0.0.0/22]", fmt.Sprint(res))
+		assert.Equal(t, []*Subnet{low, high}, res[0].Covers)
+	})
+
+	t.Run("Budget", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		var nodes []*Subnet
+		for _, cidr := range []string{"10.0.0.0/24", "10.0.1.0/25", "10.0.2.0/24", "10.0.12.0/24"} {
+			sn, err := s.AddReservation(cidr, cidr)
+			assert.NoError(t, err)
+			nodes = append(nodes, sn)
+		}
+
+		res, err := Summarize(nodes, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, "[10.0.0.0/24 10.0.1.0/25 10.0.2.0/24 10.0.12.0/24]", fmt.Sprint(res))
+
+		res, err = Summarize(nodes, 128)
+		assert.NoError(t, err)
+		assert.Equal(t, "[10.0.0.0/23 (extra 10.0.1.128/25) 10.0.2.0/24 10.0.12.0/24]", fmt.Sprint(res))
+		assert.Equal(t, 128, res[0].ExtraAddresses)
+
+		res, err = Summarize(nodes, 384)
+		assert.NoError(t, err)
+		assert.Equal(t, "[10.0.0.0/22 (extra 10.0.1.128/25, 10.0.3.0/24) 10.0.12.0/24]", fmt.Sprint(res))
+		assert.Equal(t, 384, res[0].ExtraAddresses)
+
+		res, err = Summarize(nodes, 4096)
+		assert.NoError(t, err)
+		assert.Equal(t, "[10.0.0.0/20 (extra 10.0.1.128/25, 10.0.3.0/24, 10.0.4.0/22, 10.0.8.0/22, 10.0.13.0/24, 10.0.14.0/23)]", fmt.Sprint(res))
+		assert.Equal(t, 16*256-3*256-128, res[0].ExtraAddresses)
+	})
+
+	t.Run("Greedy", func(t *testing.T) {
+		s, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		// Aggregating within 10.0.0.0/24 takes 96 extra addresses, while aggregating the /25s would take far more
+		var nodes []*Subnet
+		for _, cidr := range []string{"10.0.0.0/26", "10.0.0.64/27", "10.0.0.192/26", "10.0.1.0/25", "10.0.2.0/25"} {
+			sn, err := s.AddReservation(cidr, cidr)
+			assert.NoError(t, err)
+			nodes = append(nodes, sn)
+		}
+
+		res, err := Summarize(nodes, 160)
+		assert.NoError(t, err)
+		assert.Equal(t, "[10.0.0.0/24 (extra 10.0.0.96/27, 10.0.0.128/26) 10.0.1.0/25 10.0.2.0/25]", fmt.Sprint(res))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		res, err := Summarize(nil, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("DifferentTrees", func(t *testing.T) {
+		a, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+		b, err := Parse("10.0.0.0/16")
+		assert.NoError(t, err, "parse should return no error")
+
+		_, err = Summarize([]*Subnet{a, b}, 0)
+		assert.ErrorIs(t, err, ErrRootMismatch)
+	})
+}
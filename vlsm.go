@@ -0,0 +1,123 @@
+package subnetcalc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var ErrInvalidHostCount = errors.New("invalid host count")
+
+// HostRequirement is a named subnet needed for a number of hosts
+type HostRequirement struct {
+	Name  string
+	Hosts int
+
+	// PointToPoint allows a /31 for links of two hosts, as described in RFC 3021
+	PointToPoint bool
+}
+
+// VLSMAllocation is a subnet allocated for a HostRequirement
+type VLSMAllocation struct {
+	Name    string
+	Hosts   int
+	Subnet  *Subnet
+	FirstIP string // First usable IP
+	LastIP  string // Last usable IP
+	Usable  int    // Number of usable host addresses
+	Wasted  int    // Number of usable host addresses beyond the requirement
+}
+
+// VLSMPlan is the result of PlanVLSM, in allocation order
+type VLSMPlan []VLSMAllocation
+
+// PlanVLSM allocates and reserves the smallest subnet fitting each host requirement, largest subnets first to
+// pack them tightly. Network and broadcast addresses are not usable for hosts, except in /31 point-to-point
+// links. If any requirement cannot be met, the reservations already made are released and an error returned.
+func PlanVLSM(root *Subnet, requirements []HostRequirement) (VLSMPlan, error) {
+	sizes := make([]int, len(requirements))
+	order := make([]int, len(requirements))
+	for i, req := range requirements {
+		size, err := hostsToSize(req.Hosts, req.PointToPoint)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", req.Name, err)
+		}
+		sizes[i] = size
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return sizes[order[a]] < sizes[order[b]]
+	})
+
+	plan := make(VLSMPlan, 0, len(requirements))
+	for _, i := range order {
+		req := requirements[i]
+
+		sn, err := root.FindFreeAndReserve(sizes[i], req.Name)
+		if err != nil {
+			for _, alloc := range plan {
+				_ = alloc.Subnet.UnReserve()
+			}
+			return nil, fmt.Errorf("%s: %w", req.Name, err)
+		}
+
+		first, last := sn.FirstIP(), sn.LastIP()
+		if sn.Size() == 31 {
+			first, last = inetNToA(sn.network()), inetNToA(sn.broadcast())
+		}
+
+		plan = append(plan, VLSMAllocation{
+			Name:    req.Name,
+			Hosts:   req.Hosts,
+			Subnet:  sn,
+			FirstIP: first,
+			LastIP:  last,
+			Usable:  usableHosts(sn.Size()),
+			Wasted:  usableHosts(sn.Size()) - req.Hosts,
+		})
+	}
+
+	return plan, nil
+}
+
+// String renders the plan as a table
+func (p VLSMPlan) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tHOSTS\tCIDR\tFIRST IP\tLAST IP\tUSABLE\tWASTED")
+	for _, a := range p {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d\t%d\n", a.Name, a.Hosts, a.Subnet.CIDR(), a.FirstIP, a.LastIP, a.Usable, a.Wasted)
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+// hostsToSize returns the size of the smallest subnet with room for the number of hosts
+func hostsToSize(hosts int, pointToPoint bool) (int, error) {
+	if hosts < 1 {
+		return 0, fmt.Errorf("%w: %d", ErrInvalidHostCount, hosts)
+	}
+	if pointToPoint && hosts <= 2 {
+		return 31, nil
+	}
+
+	for size := 30; size >= 0; size-- {
+		if usableHosts(size) >= hosts {
+			return size, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %d", ErrInvalidHostCount, hosts)
+}
+
+// usableHosts returns the number of host addresses in a subnet of the given size
+func usableHosts(size int) int {
+	switch size {
+	case 32:
+		return 1
+	case 31:
+		return 2
+	}
+	return inetSubnetAddresses(size) - 2
+}
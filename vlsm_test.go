@@ -0,0 +1,78 @@
+package subnetcalc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PlanVLSM(t *testing.T) {
+	t.Run("Ok", func(t *testing.T) {
+		s, err := Parse("192.168.0.0/24")
+		assert.NoError(t, err, "parse should return no error")
+
+		plan, err := PlanVLSM(s, []HostRequirement{
+			{Name: "office", Hosts: 10},
+			{Name: "link-a", Hosts: 2, PointToPoint: true},
+			{Name: "servers", Hosts: 60},
+			{Name: "clients", Hosts: 120},
+			{Name: "link-b", Hosts: 2},
+		})
+		assert.NoError(t, err)
+
+		assert.Equal(t, `NAME     HOSTS  CIDR              FIRST IP       LAST IP        USABLE  WASTED
+clients  120    192.168.0.0/25    192.168.0.1    192.168.0.126  126     6
+servers  60     192.168.0.128/26  192.168.0.129  192.168.0.190  62      2
+office   10     192.168.0.192/28  192.168.0.193  192.168.0.206  14      4
+link-b   2      192.168.0.208/30  192.168.0.209  192.168.0.210  2       0
+link-a   2      192.168.0.212/31  192.168.0.212  192.168.0.213  2       0
+`, plan.String())
+
+		assert.Equal(t, "clients", plan[0].Subnet.Reservation())
+		assert.Len(t, s.Collect(SelectReserved()), 5)
+	})
+
+	t.Run("Does not fit", func(t *testing.T) {
+		s, err := Parse("192.168.0.0/24")
+		assert.NoError(t, err, "parse should return no error")
+
+		_, err = PlanVLSM(s, []HostRequirement{
+			{Name: "clients", Hosts: 120},
+			{Name: "servers", Hosts: 120},
+			{Name: "office", Hosts: 10},
+		})
+		assert.ErrorIs(t, err, ErrDidNotFindSubnet)
+		assert.ErrorContains(t, err, "office")
+		assert.False(t, s.HasChildReservations(), "reservations are rolled back")
+	})
+
+	t.Run("Invalid host count", func(t *testing.T) {
+		s, err := Parse("192.168.0.0/24")
+		assert.NoError(t, err, "parse should return no error")
+
+		_, err = PlanVLSM(s, []HostRequirement{{Name: "none", Hosts: 0}})
+		assert.ErrorIs(t, err, ErrInvalidHostCount)
+	})
+}
+
+func Test_hostsToSize(t *testing.T) {
+	tests := []struct {
+		hosts        int
+		pointToPoint bool
+		want         int
+	}{
+		{1, false, 30},
+		{2, false, 30},
+		{2, true, 31},
+		{3, true, 29},
+		{14, false, 28},
+		{15, false, 27},
+		{254, false, 24},
+		{255, false, 23},
+	}
+	for _, tt := range tests {
+		got, err := hostsToSize(tt.hosts, tt.pointToPoint)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got, "hosts %d", tt.hosts)
+	}
+}